@@ -2,15 +2,120 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/molizz/myto"
+	"github.com/molizz/myto/convertor"
+	"github.com/molizz/myto/gormmodel"
 )
 
 func main() {
+	prefixIndex := flag.String("prefix-index", convertor.PrefixIndexFullColumn.String(),
+		"prefix index strategy: full, substr or skip")
+	skipFulltext := flag.Bool("skip-fulltext", false, "skip FULLTEXT indexes when the target has no full-text support")
+	skipSpatial := flag.Bool("skip-spatial", false, "skip SPATIAL indexes when the target has no spatial support")
+	storedAsVirtual := flag.Bool("stored-as-virtual", false, "convert STORED generated columns to virtual columns instead of triggers")
+	charLength := flag.String("char-length", convertor.CharLengthChar.String(),
+		"length semantics of multi-byte char/varchar columns: char or byte")
+	targetCharset := flag.String("target-charset", convertor.DMCharsetUTF8, "charset of the target DM database: UTF-8 or GB18030")
+	ciUnique := flag.String("ci-unique", convertor.CaseInsensitiveUpper.String(),
+		"case-insensitive unique index strategy: upper, report or ignore")
+	tinyintBool := flag.String("tinyint-bool", convertor.TinyintBoolBit.String(),
+		"type of tinyint(1) columns: bit, boolean or tinyint")
+	tinyintBoolOverrides := flag.String("tinyint-bool-override", "",
+		"comma separated per table or column tinyint(1) types, e.g. 'user=tinyint,task.is_done=boolean'")
+	spatialType := flag.String("spatial-type", convertor.SpatialSysgeo.String(),
+		"type of spatial columns: sysgeo (SYSGEO.ST_GEOMETRY family) or wkb (BLOB)")
+	textAsVarchar := flag.Bool("text-as-varchar", false, "convert tinytext columns to VARCHAR2 instead of TEXT")
+	typeMapping := flag.String("type-mapping", "", "JSON or YAML file overriding the built-in type mapping")
+	diffFrom := flag.String("diff", "", "old mysql DDL file, output DM ALTER statements migrating it to the DDL read from stdin")
+	downFile := flag.String("down", "", "also write the rollback (down) DM statements to this file")
+	migrationsDir := flag.String("migrations", "", "golang-migrate or goose mysql migrations directory to convert, instead of reading stdin")
+	gormModels := flag.String("gorm", "", "go file or package directory of gorm models to convert, instead of reading stdin")
+	outDir := flag.String("out-dir", "", "output directory of the converted migrations, flyway scripts or per table DM scripts with a manifest")
+	flywayVersion := flag.Int("flyway-version", 1, "version of the first flyway script")
+	liquibaseAuthor := flag.String("liquibase-author", "myto", "author of the liquibase changeSets")
+	goPackage := flag.String("go-package", "model", "package name of the generated go structs")
+	goTags := flag.String("go-tags", "gorm,db", "comma separated struct tags of the generated go structs")
+	format := flag.String("format", "dmdb", "output format: dmdb (DM DDL), json (parsed schema), mysql (DM DDL converted back to mysql), "+
+		"flyway (versioned scripts written to -out-dir), liquibase-xml, liquibase-yaml or go (go structs)")
+	flag.Parse()
+
+	var options convertor.DMDBOptions
+	var err error
+	options.PrefixIndex, err = convertor.ParsePrefixIndexStrategy(*prefixIndex)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	options.SkipFulltextIndex = *skipFulltext
+	options.SkipSpatialIndex = *skipSpatial
+	options.StoredGeneratedAsVirtual = *storedAsVirtual
+	options.TextAsVarchar = *textAsVarchar
+	if options.CharLength, err = convertor.ParseCharLengthSemantics(*charLength); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if options.TargetCharset, err = convertor.ParseDMCharset(*targetCharset); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if options.CaseInsensitiveUnique, err = convertor.ParseCaseInsensitiveUniqueStrategy(*ciUnique); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if options.TinyintBool, err = convertor.ParseTinyintBoolMapping(*tinyintBool); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if options.SpatialType, err = convertor.ParseSpatialTypeMapping(*spatialType); err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if len(*typeMapping) > 0 {
+		if options.TypeMapping, err = convertor.LoadTypeMapping(*typeMapping); err != nil {
+			log.Fatalf("%+v", err)
+		}
+	}
+	if options.TinyintBoolOverrides, err = parseTinyintBoolOverrides(*tinyintBoolOverrides); err != nil {
+		log.Fatalf("%+v", err)
+	}
+
+	if len(*migrationsDir) > 0 {
+		if len(*outDir) == 0 {
+			log.Fatalf("-out-dir is required by -migrations")
+		}
+		files, err := convertor.ConvertMigrationDir(*migrationsDir, *outDir, options)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		var lossy int
+		for _, f := range files {
+			for _, d := range f.Diagnostics {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", f.Path, d.String())
+			}
+			if len(f.SkippedStatements()) > 0 {
+				lossy++
+			}
+		}
+		if lossy > 0 {
+			log.Fatalf("%d migration files have statements that were not converted", lossy)
+		}
+		return
+	}
+
+	if len(*gormModels) > 0 {
+		output, diagnostics, err := gormmodel.ToDMDB(options, *gormModels)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		fmt.Println(output)
+		for _, d := range diagnostics {
+			fmt.Fprintln(os.Stderr, d.String())
+		}
+		return
+	}
+
 	// if len(os.Args) < 2 {
 	// 	log.Panicf("must input DDL sql, %d", len(os.Args))
 	// }
@@ -21,9 +126,123 @@ func main() {
 		log.Panicf("STD input is required, %+v", err)
 	}
 
-	output, err := myto.New(string(input), true).ToDMDB()
+	m := myto.New(string(input), true).WithDMDBOptions(options)
+	var output string
+	switch *format {
+	case "dmdb":
+		if len(*outDir) > 0 && (len(*diffFrom) > 0 || len(*downFile) > 0) {
+			log.Fatalf("-out-dir can not be used with -diff or -down")
+		}
+		var down string
+		switch {
+		case len(*diffFrom) > 0:
+			oldSQL, readErr := os.ReadFile(*diffFrom)
+			if readErr != nil {
+				log.Fatalf("%+v", readErr)
+			}
+			if len(*downFile) > 0 {
+				output, down, err = m.ToDMDBDiffWithDown(string(oldSQL))
+			} else {
+				output, err = m.ToDMDBDiff(string(oldSQL))
+			}
+		case len(*downFile) > 0:
+			output, down, err = m.ToDMDBWithDown()
+		case len(*outDir) > 0:
+			var scripts []*convertor.TableScript
+			if scripts, err = m.ToTableScripts(); err == nil {
+				err = writeTableScripts(*outDir, scripts)
+			}
+			if err == nil {
+				output = strings.TrimRight(convertor.TableScriptsManifest(scripts), "\n")
+			}
+		default:
+			output, err = m.ToDMDB()
+		}
+		if err == nil && len(*downFile) > 0 {
+			if writeErr := os.WriteFile(*downFile, []byte(down+"\n"), 0644); writeErr != nil {
+				log.Fatalf("%+v", writeErr)
+			}
+		}
+	case "json":
+		output, err = m.ToJSON()
+	case "flyway":
+		if len(*outDir) == 0 {
+			log.Fatalf("-out-dir is required by -format flyway")
+		}
+		var scripts []*convertor.FlywayScript
+		if scripts, err = m.ToFlyway(*flywayVersion); err == nil {
+			err = writeFlywayScripts(*outDir, scripts)
+		}
+		if err == nil {
+			var names []string
+			for _, script := range scripts {
+				names = append(names, script.FileName())
+			}
+			output = strings.Join(names, "\n")
+		}
+	case "liquibase-xml":
+		output, err = m.ToLiquibase(convertor.LiquibaseXML, *liquibaseAuthor)
+	case "liquibase-yaml":
+		output, err = m.ToLiquibase(convertor.LiquibaseYAML, *liquibaseAuthor)
+	case "go":
+		output, err = m.ToGoStructs(convertor.GoStructOptions{Package: *goPackage, Tags: strings.Split(*goTags, ",")})
+	case "mysql":
+		output, err = m.ToMysql()
+	default:
+		log.Fatalf("unknown output format '%s'", *format)
+	}
 	if err != nil {
 		panic(err)
 	}
 	fmt.Println(output)
+
+	for _, d := range m.Diagnostics() {
+		fmt.Fprintln(os.Stderr, d.String())
+	}
+}
+
+func writeFlywayScripts(dir string, scripts []*convertor.FlywayScript) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, script.FileName()), []byte(script.SQL), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTableScripts 每个表写入一个脚本，清单文件按执行顺序列出脚本
+func writeTableScripts(dir string, scripts []*convertor.TableScript) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, script.FileName), []byte(script.SQL()), 0644); err != nil {
+			return err
+		}
+	}
+	manifest := convertor.TableScriptsManifest(scripts)
+	return os.WriteFile(filepath.Join(dir, convertor.TableScriptManifest), []byte(manifest), 0644)
+}
+
+// parseTinyintBoolOverrides 解析 table=bit,table.column=tinyint 形式的设置
+func parseTinyintBoolOverrides(s string) (map[string]convertor.TinyintBoolMapping, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	result := map[string]convertor.TinyintBoolMapping{}
+	for _, item := range strings.Split(s, ",") {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid tinyint(1) override '%s'", item)
+		}
+		m, err := convertor.ParseTinyintBoolMapping(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSpace(kv[0])] = m
+	}
+	return result, nil
 }
@@ -0,0 +1,141 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 达梦数据库的字符集
+const (
+	DMCharsetUTF8    = "UTF-8"
+	DMCharsetGB18030 = "GB18030"
+)
+
+// CharLengthSemantics 多字节字符集下字符串长度的转换方式
+// mysql 的 varchar(n) 按字符计算长度，达梦的 VARCHAR2(n) 默认按字节计算长度
+type CharLengthSemantics int
+
+const (
+	// CharLengthChar 输出 VARCHAR2(n CHAR)
+	CharLengthChar CharLengthSemantics = iota
+	// CharLengthByte 按目标库字符集换算为字节长度
+	CharLengthByte
+)
+
+var charLengthSemanticsNames = map[CharLengthSemantics]string{
+	CharLengthChar: "char",
+	CharLengthByte: "byte",
+}
+
+func (s CharLengthSemantics) String() string {
+	if name, found := charLengthSemanticsNames[s]; found {
+		return name
+	}
+	return fmt.Sprintf("CharLengthSemantics(%d)", int(s))
+}
+
+// ParseCharLengthSemantics 将 char/byte 解析为对应的长度语义
+func ParseCharLengthSemantics(name string) (CharLengthSemantics, error) {
+	for s, n := range charLengthSemanticsNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown char length semantics '%s'", name)
+}
+
+// ParseDMCharset 解析目标库字符集，空字符串为 UTF-8
+func ParseDMCharset(name string) (string, error) {
+	switch strings.ToUpper(strings.ReplaceAll(name, "-", "")) {
+	case "", "UTF8":
+		return DMCharsetUTF8, nil
+	case "GB18030":
+		return DMCharsetGB18030, nil
+	}
+	return "", fmt.Errorf("unknown DM charset '%s'", name)
+}
+
+// mysqlMultiByteCharsets mysql 字符集中每个字符在达梦各字符集下最多占用的字节数。
+// latin1 在 mysql 中为 cp1252，0x80 以上的字符（如 é、€）在达梦中占用多个字节，同样需要按字符计算长度；
+// 未列出的字符集（ascii、binary 等）按单字节处理，长度保持不变
+var mysqlMultiByteCharsets = map[string]map[string]int{
+	"latin1":  {DMCharsetUTF8: 3, DMCharsetGB18030: 2},
+	"utf8":    {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"utf8mb3": {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"utf8mb4": {DMCharsetUTF8: 4, DMCharsetGB18030: 4},
+	"gbk":     {DMCharsetUTF8: 3, DMCharsetGB18030: 2},
+	"gb2312":  {DMCharsetUTF8: 3, DMCharsetGB18030: 2},
+	"gb18030": {DMCharsetUTF8: 4, DMCharsetGB18030: 4},
+	"big5":    {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"ucs2":    {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"utf16":   {DMCharsetUTF8: 4, DMCharsetGB18030: 4},
+	"utf16le": {DMCharsetUTF8: 4, DMCharsetGB18030: 4},
+	"utf32":   {DMCharsetUTF8: 4, DMCharsetGB18030: 4},
+	"ujis":    {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"sjis":    {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"cp932":   {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"eucjpms": {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+	"euckr":   {DMCharsetUTF8: 3, DMCharsetGB18030: 4},
+}
+
+// 未指定字符集时按 mysql 8.0 的默认字符集处理
+const mysqlDefaultCharset = "utf8mb4"
+
+// charsetMaxBytes 返回 mysql 字符集的一个字符在达梦字符集下最多占用的字节数
+func charsetMaxBytes(charset, dmCharset string) int {
+	if len(charset) == 0 {
+		charset = mysqlDefaultCharset
+	}
+	if bytes, found := mysqlMultiByteCharsets[strings.ToLower(charset)]; found {
+		if n, found := bytes[dmCharset]; found {
+			return n
+		}
+		return bytes[DMCharsetUTF8]
+	}
+	return 1
+}
+
+// mysqlStringTypes 受字符集、排序规则影响的列类型
+var mysqlStringTypes = map[string]struct{}{
+	"char":       {},
+	"varchar":    {},
+	"tinytext":   {},
+	"text":       {},
+	"mediumtext": {},
+	"longtext":   {},
+	"enum":       {},
+	"set":        {},
+}
+
+// columnCollation 返回列的排序规则，依次使用列的排序规则、列的字符集的默认排序规则、表的排序规则、
+// 表的字符集的默认排序规则，字符集的默认排序规则返回 <charset>_general_ci
+func columnCollation(columnType ColumnType, tableOptions *TableOptions) string {
+	if len(columnType.Collate) > 0 {
+		return columnType.Collate
+	}
+	if len(columnType.Charset) > 0 {
+		return defaultCollation(columnType.Charset)
+	}
+	if tableOptions != nil {
+		if collation := tableOptions.Collate(); len(collation) > 0 {
+			return collation
+		}
+		if charset := tableOptions.Charset(); len(charset) > 0 {
+			return defaultCollation(charset)
+		}
+	}
+	return defaultCollation(mysqlDefaultCharset)
+}
+
+func defaultCollation(charset string) string {
+	charset = strings.ToLower(charset)
+	if charset == "binary" {
+		return charset
+	}
+	return charset + "_general_ci"
+}
+
+// isCaseInsensitiveCollation *_ci 排序规则比较时忽略大小写，*_bin、*_cs 及 binary 区分大小写
+func isCaseInsensitiveCollation(collation string) bool {
+	return strings.HasSuffix(strings.ToLower(collation), "_ci")
+}
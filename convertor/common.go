@@ -1,6 +1,7 @@
 package convertor
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
@@ -45,15 +46,81 @@ func parseMysqlTableOptions(options string) *TableOptions {
 	return &TableOptions{result}
 }
 
-func buildPKName(tableName string, indexColumns []*sqlparser.IndexColumn) string {
+// Get 忽略大小写获取表选项
+func (t *TableOptions) Get(key string) (string, bool) {
+	for k, v := range t.options {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Charset 返回表的默认字符集，未指定字符集时从排序规则中推断
+func (t *TableOptions) Charset() string {
+	// DEFAULT CHARSET=x 或 DEFAULT CHARACTER SET=x
+	for _, key := range []string{"charset", "set"} {
+		if v, found := t.Get(key); found {
+			return strings.ToLower(v)
+		}
+	}
+	return collationCharset(t.Collate())
+}
+
+// Collate 返回表的默认排序规则
+func (t *TableOptions) Collate() string {
+	v, _ := t.Get("collate")
+	return strings.ToLower(v)
+}
+
+// Map 返回规范化后的表选项：key 转换为小写，DEFAULT CHARACTER SET 等拆分出的无值项被忽略，
+// 字符集统一使用 charset
+func (t *TableOptions) Map() map[string]string {
+	result := map[string]string{}
+	for k, v := range t.options {
+		if len(v) == 0 {
+			continue
+		}
+		k = strings.ToLower(k)
+		if k == "set" {
+			k = "charset"
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// MarshalJSON 按 Map 输出表选项
+func (t *TableOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Map())
+}
+
+// UnmarshalJSON 读取 MarshalJSON 输出的表选项
+func (t *TableOptions) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.options)
+}
+
+// collationCharset 返回排序规则对应的字符集，例如 utf8mb4_general_ci 对应 utf8mb4
+func collationCharset(collation string) string {
+	if i := strings.IndexByte(collation, '_'); i > 0 {
+		return collation[:i]
+	}
+	return collation
+}
+
+func buildPKName(tableName string, columns []string) string {
 	var sb strings.Builder
 	sb.WriteString("pk_")
 	sb.WriteString(tableName)
 	sb.WriteString("_")
-	sb.WriteString(joinIndexColumns(indexColumns, "_", nil))
+	sb.WriteString(strings.Join(columns, "_"))
 	return sb.String()
 }
 
+func buildFKName(tableName, oldFKName string) string {
+	return buildIdxName("fk_", tableName, oldFKName)
+}
+
 func buildIdxName(prefix, tableName, oldIndexName string) string {
 	var sb strings.Builder
 	sb.WriteString(prefix)
@@ -63,21 +130,20 @@ func buildIdxName(prefix, tableName, oldIndexName string) string {
 	return sb.String()
 }
 
-func buildIndexColumns(indexColumns []*sqlparser.IndexColumn, keyFn func(columnName string) string) string {
-	return joinIndexColumns(indexColumns, ", ", keyFn)
+func joinColumnNames(columns []string) string {
+	var names []string
+	for _, col := range columns {
+		names = append(names, buildColumnName(col))
+	}
+	return strings.Join(names, ", ")
 }
 
-func joinIndexColumns(indexColumns []*sqlparser.IndexColumn, sep string, keyFn func(columnName string) string) string {
-	var sb strings.Builder
-	for i, col := range indexColumns {
-		columnName := col.Column.String()
-		if keyFn != nil {
-			columnName = keyFn(columnName)
-		}
-		sb.WriteString(columnName)
-		if i != (len(indexColumns) - 1) {
-			sb.WriteString(sep)
-		}
-	}
-	return sb.String()
+// isFulltextIndex sqlparser 的 IndexInfo 中没有全文索引的标记，全文索引由预处理生成，通过 Type 区分
+func isFulltextIndex(info *sqlparser.IndexInfo) bool {
+	return strings.HasPrefix(info.Type, "fulltext")
+}
+
+// quoteSQLString 输出 SQL 字符串字面量
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
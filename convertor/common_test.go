@@ -13,3 +13,10 @@ func Test_parseMysqlTableOptions(t *testing.T) {
 	assert.Equal(t, "InnoDB", result.options["ENGINE"])
 	assert.Equal(t, "标签库", result.options["comment"])
 }
+
+func TestTableOptions_Charset(t *testing.T) {
+	assert.Equal(t, "latin1", parseMysqlTableOptions(` ENGINE=InnoDB default charset=latin1 collate=latin1_bin`).Charset())
+	assert.Equal(t, "utf8mb4", parseMysqlTableOptions(` ENGINE=InnoDB default character set=utf8mb4`).Charset())
+	assert.Equal(t, "utf8mb4", parseMysqlTableOptions(` ENGINE=InnoDB collate=utf8mb4_general_ci`).Charset())
+	assert.Equal(t, "", parseMysqlTableOptions(` ENGINE=InnoDB`).Charset())
+}
@@ -29,14 +29,20 @@ func (c *Container) Append(f Element) {
 	c.list = append(c.list, f)
 }
 
-// Render 将container输出为string
+// Render 将container输出为string，Format 结果为空的元素会被忽略
 func (c *Container) Render() string {
 	var sb strings.Builder
 
-	for i, e := range c.list {
-		sb.WriteString(e.Format())
+	var lines []string
+	for _, e := range c.list {
+		if line := e.Format(); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	for i, line := range lines {
+		sb.WriteString(line)
 		if len(c.lineSuffix) > 0 {
-			if c.ignoreLastLineSuffix && i == len(c.list)-1 {
+			if c.ignoreLastLineSuffix && i == len(lines)-1 {
 				break
 			}
 			sb.WriteString(c.lineSuffix)
@@ -0,0 +1,80 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DiagnosticLevel int
+
+const (
+	DiagnosticInfo DiagnosticLevel = iota
+	DiagnosticWarning
+)
+
+func (l DiagnosticLevel) String() string {
+	switch l {
+	case DiagnosticInfo:
+		return "INFO"
+	case DiagnosticWarning:
+		return "WARN"
+	}
+	return fmt.Sprintf("DiagnosticLevel(%d)", int(l))
+}
+
+// Diagnostic 转换过程中产生的提示，例如被改写或跳过的索引
+type Diagnostic struct {
+	Level   DiagnosticLevel
+	Table   string
+	Object  string // 列名、索引名等，可为空
+	Message string
+	Skipped bool // 语句或其中的部分操作未被转换，转换结果中缺少这些内容
+}
+
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	sb.WriteString(d.Level.String())
+	sb.WriteString("] ")
+	sb.WriteString(d.Table)
+	if len(d.Object) > 0 {
+		sb.WriteString(".")
+		sb.WriteString(d.Object)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(d.Message)
+	return sb.String()
+}
+
+// Diagnostics 收集单次转换产生的提示
+type Diagnostics struct {
+	list []Diagnostic
+}
+
+func (d *Diagnostics) Infof(table, object, format string, args ...interface{}) {
+	d.add(DiagnosticInfo, table, object, format, args...)
+}
+
+func (d *Diagnostics) Warnf(table, object, format string, args ...interface{}) {
+	d.add(DiagnosticWarning, table, object, format, args...)
+}
+
+// Skipf 语句或其中的部分操作未被转换，按警告给出
+func (d *Diagnostics) Skipf(table, format string, args ...interface{}) {
+	d.add(DiagnosticWarning, table, "", format, args...)
+	d.list[len(d.list)-1].Skipped = true
+}
+
+func (d *Diagnostics) add(level DiagnosticLevel, table, object, format string, args ...interface{}) {
+	d.list = append(d.list, Diagnostic{
+		Level:   level,
+		Table:   table,
+		Object:  object,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// List 返回已收集的提示
+func (d *Diagnostics) List() []Diagnostic {
+	return d.list
+}
@@ -3,11 +3,9 @@ package convertor
 import (
 	"fmt"
 	"io"
-	"log"
-	"math"
-	"strconv"
+	"regexp"
 	"strings"
-	"sync"
+	"unicode/utf8"
 
 	"github.com/xwb1989/sqlparser"
 )
@@ -16,10 +14,13 @@ var _ Element = (*dmdbDropTableIfExists)(nil)
 var _ Element = (*dmdbCreateTable)(nil)
 var _ Element = (*dmdbTableColumn)(nil)
 var _ Element = (*dmdbColumnComment)(nil)
+var _ Element = (*dmdbForeignKey)(nil)
+var _ Element = (*dmdbDropForeignKey)(nil)
+var _ Element = (*dmdbGeneratedColumnTrigger)(nil)
 
 var mysqlWithDMDatatypeMapping = map[string]string{
 	"varchar":   "varchar2",
-	"varbinary": "longvarbinary",
+	"varbinary": "varbinary",
 	"char":      "char",
 	"binary":    "binary",
 
@@ -31,17 +32,19 @@ var mysqlWithDMDatatypeMapping = map[string]string{
 	"smallint":  "smallint",
 	"mediumint": "int",
 	"decimal":   "numeric",
+	"numeric":   "numeric",
 	"dec":       "dec",
-	"float":     "float",
+	"float":     "real",
 	"double":    "double",
+	"real":      "double",
 
+	"tinytext":   "text",
 	"text":       "text",
-	"longtext":   "text",
-	"tinyblob":   "blob",
-	"tinytext":   "varchar2",
+	"mediumtext": "text",
+	"longtext":   "clob",
+	"tinyblob":   "varbinary",
 	"blob":       "blob",
 	"mediumblob": "blob",
-	"mediumtext": "text",
 	"longblob":   "blob",
 	"bool":       "boolean",
 	"boolean":    "boolean",
@@ -53,221 +56,822 @@ var mysqlWithDMDatatypeMapping = map[string]string{
 	"set":  "varchar2",
 
 	"json": "text",
-}
 
-// 只执行一次的json约束
-var jsonConstraintOnce sync.Once
+	"geometry":           "SYSGEO.ST_GEOMETRY",
+	"point":              "SYSGEO.ST_POINT",
+	"linestring":         "SYSGEO.ST_LINESTRING",
+	"polygon":            "SYSGEO.ST_POLYGON",
+	"multipoint":         "SYSGEO.ST_MULTIPOINT",
+	"multilinestring":    "SYSGEO.ST_MULTILINESTRING",
+	"multipolygon":       "SYSGEO.ST_MULTIPOLYGON",
+	"geometrycollection": "SYSGEO.ST_GEOMCOLLECTION",
+}
 
 type DMDB struct {
-	sqlTokenizer *sqlparser.Tokenizer
+	sql               string
+	schema            *Schema // 不为空时直接转换 schema，不再解析 sql
+	options           DMDBOptions
+	diagnostics       Diagnostics
+	parserDiagnostics []Diagnostic // NewDMDB 读取 sqlTokenizer 时产生的提示，每次转换都会给出
 }
 
+// NewDMDB 转换 sqlTokenizer 中的语句。sqlTokenizer 不保留原始的 sql，只能转换 sqlparser 能够解析的语句，
+// 外键、FULLTEXT 索引、生成列等需要预处理的语法无法转换，新代码应使用 NewDMDBFromSQL
 func NewDMDB(sqlTokenizer *sqlparser.Tokenizer) *DMDB {
-	return &DMDB{sqlTokenizer: sqlTokenizer}
+	var statements []string
+	var diagnostics Diagnostics
+	for {
+		st, err := sqlparser.ParseNext(sqlTokenizer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			diagnostics.Skipf("", "statement not supported by the parser skipped: %v", err)
+			continue
+		}
+		if st != nil {
+			statements = append(statements, sqlparser.String(st))
+		}
+	}
+	o := NewDMDBFromSQL(strings.Join(statements, ";\n"))
+	o.parserDiagnostics = diagnostics.List()
+	return o
+}
+
+func NewDMDBFromSQL(sql string) *DMDB {
+	return NewDMDBWithOptions(sql, DMDBOptions{})
+}
+
+func NewDMDBWithOptions(sql string, options DMDBOptions) *DMDB {
+	return &DMDB{sql: sql, options: options}
+}
+
+// NewDMDBWithSchema 转换已构造的 Schema，用于 mysql DDL 之外的来源（如 gorm 模型）
+func NewDMDBWithSchema(schema *Schema, options DMDBOptions) *DMDB {
+	return &DMDB{schema: schema, options: options}
+}
+
+// parseSchema 返回要转换的 Schema，解析 sql 时的提示记录到 diagnostics
+func (o *DMDB) parseSchema(diagnostics *Diagnostics) *Schema {
+	if o.schema != nil {
+		return o.schema
+	}
+	return parseMysqlSchema(o.sql, diagnostics)
+}
+
+func (o *DMDB) targetCharset() string {
+	if len(o.options.TargetCharset) == 0 {
+		return DMCharsetUTF8
+	}
+	return o.options.TargetCharset
+}
+
+// Diagnostics 返回最近一次转换产生的提示
+func (o *DMDB) Diagnostics() []Diagnostic {
+	return o.diagnostics.List()
+}
+
+// resetDiagnostics 清除上一次转换的提示，同一个 DMDB 多次转换时提示不会重复
+func (o *DMDB) resetDiagnostics() {
+	o.diagnostics = Diagnostics{list: append([]Diagnostic(nil), o.parserDiagnostics...)}
 }
 
 func (o *DMDB) Exec() (string, error) {
+	o.resetDiagnostics()
 	var container = NewContainerWithSuffix("\n/\n", true)
+	for _, element := range o.elements() {
+		container.Append(element)
+	}
+	return container.Render(), nil
+}
 
-	for {
-		st, err := sqlparser.ParseNext(o.sqlTokenizer)
-		if err != nil {
-			if err == io.EOF {
-				break
+// elements 按输出顺序返回转换得到的语句
+func (o *DMDB) elements() []Element {
+	var elements []Element
+	// 外键在所有表创建完成之后再添加，这样就不依赖建表语句的顺序
+	var foreignKeys []*ForeignKey
+
+	schema := o.parseSchema(&o.diagnostics)
+	for _, stmt := range schema.Statements {
+		switch stmt.Kind {
+		case StatementDropTable:
+			elements = append(elements, &dmdbDropTableIfExists{DropTable: stmt.Drop})
+		case StatementCreateTable:
+			elements = append(elements, newDMDBCreateTable(o, stmt.Table))
+			foreignKeys = append(foreignKeys, stmt.Table.ForeignKeys...)
+		case StatementAlterTable:
+			alter := stmt.Alter
+			for _, name := range alter.DropForeignKeys {
+				var dropped bool
+				if foreignKeys, dropped = removeForeignKey(foreignKeys, alter.Name, name); !dropped {
+					elements = append(elements, &dmdbDropForeignKey{tableName: alter.Name, name: name})
+				}
+			}
+			foreignKeys = append(foreignKeys, alter.AddForeignKeys...)
+			for _, partition := range alter.Partitions {
+				elements = append(elements, &dmdbAlterPartition{conv: o, tableName: alter.Name, AlterPartition: partition})
 			}
 		}
+	}
 
-		// TODO view table
-		switch ddl := st.(type) {
-		case *sqlparser.DDL:
-			switch ddl.Action {
-			case sqlparser.DropStr:
-				container.Append(&dmdbDropTableIfExists{DDL: ddl})
-			case sqlparser.CreateStr:
-				container.Append(&dmdbCreateTable{
-					DDL:                     ddl,
-					columnContainer:         NewContainerWithSuffix(",\n", true),
-					columnCommentsContainer: NewContainerWithSuffix("\n/\n", true),
-					indexContainer:          NewContainerWithSuffix("\n", false),
-				})
-			}
+	for _, fk := range foreignKeys {
+		elements = append(elements, &dmdbForeignKey{conv: o, ForeignKey: fk})
+	}
+	return elements
+}
+
+// removeForeignKey 删除本次转换中尚未输出的外键，外键不存在时返回 false
+func removeForeignKey(foreignKeys []*ForeignKey, tableName, name string) ([]*ForeignKey, bool) {
+	for i, fk := range foreignKeys {
+		if fk.Table == tableName && fk.Name == name {
+			return append(foreignKeys[:i], foreignKeys[i+1:]...), true
 		}
 	}
-	return container.Render(), nil
+	return foreignKeys, false
 }
 
 type dmdbCreateTable struct {
-	*sqlparser.DDL
-	columnContainer         *Container // 列
-	columnCommentsContainer *Container // 列注释
-	indexContainer          *Container
-	sb                      strings.Builder
+	conv *DMDB
+	*Table
+}
+
+func newDMDBCreateTable(conv *DMDB, table *Table) *dmdbCreateTable {
+	return &dmdbCreateTable{conv: conv, Table: table}
 }
 
 func (o *dmdbCreateTable) Format() string {
-	tableName := o.NewName.Name.String()
+	var sb strings.Builder
+	var columnComments, triggers []string
+
+	for _, object := range o.objects() {
+		switch {
+		case object.Kind == DMDBObjectComment && len(object.Name) > 0:
+			columnComments = append(columnComments, object.SQL)
+		case object.Kind == DMDBObjectComment:
+			sb.WriteString(object.SQL + "\n/\n")
+		case object.Kind == DMDBObjectTrigger:
+			triggers = append(triggers, object.SQL)
+		default:
+			// 建表语句及索引
+			sb.WriteString(object.SQL + "\n")
+		}
+	}
 
-	for _, column := range o.DDL.TableSpec.Columns {
-		o.columnContainer.Append(&dmdbTableColumn{ColumnDefinition: column})
-		// 生成表中的字段注释
-		if column.Type.Comment != nil {
-			o.columnCommentsContainer.Append(&dmdbColumnComment{
-				tableName:        tableName,
-				ColumnDefinition: column,
-			})
+	sb.WriteString(strings.Join(columnComments, "\n/\n"))
+	// generated column trigger
+	if len(triggers) > 0 {
+		if len(columnComments) > 0 {
+			sb.WriteString("\n/\n")
 		}
+		sb.WriteString(strings.Join(triggers, "\n/\n"))
 	}
-	for _, index := range o.DDL.TableSpec.Indexes {
-		o.indexContainer.Append(&dmdbTableIndex{
-			tableName:       tableName,
-			IndexDefinition: index,
-		})
+	return sb.String()
+}
+
+// objects 按输出顺序返回建表语句、主键及索引、表注释、列注释和维护生成列的触发器
+func (o *dmdbCreateTable) objects() []*DMDBObject {
+	tableName := o.Table.Name
+	var columnContainer = NewContainerWithSuffix(",\n", true)
+	var columnComments []*Column
+	var triggers []*dmdbGeneratedColumnTrigger
+
+	for _, column := range o.Table.Columns {
+		tableColumn, trigger := newDMDBTableColumn(o.conv, o.Table, column)
+		columnContainer.Append(tableColumn)
+		if !tableColumn.mapped() {
+			continue
+		}
+		if trigger != nil {
+			triggers = append(triggers, trigger)
+		}
+		// 生成表中的字段注释
+		if len(column.Comment) > 0 {
+			columnComments = append(columnComments, column)
+		}
 	}
 
-	o.sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", buildTableName(tableName)))
-	o.sb.WriteString(o.columnContainer.Render())
-	o.sb.WriteString(");\n")
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", buildTableName(tableName)))
+	sb.WriteString(columnContainer.Render())
+	sb.WriteString(")")
+	sb.WriteString(o.formatPartition())
+	sb.WriteString(";")
+	objects := []*DMDBObject{{Kind: DMDBObjectTable, Table: tableName, SQL: sb.String()}}
 
 	// table index
-	o.sb.WriteString(o.indexContainer.Render())
+	for _, index := range o.Table.Indexes {
+		sql := (&dmdbTableIndex{conv: o.conv, table: o.Table, Index: index}).Format()
+		switch {
+		case len(sql) == 0:
+		case index.Kind == IndexPrimary:
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectPrimaryKey, Table: tableName, SQL: sql})
+		default:
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectIndex, Table: tableName, Name: index.Name, SQL: sql})
+		}
+	}
 
 	// table comment
-	opt := parseMysqlTableOptions(o.DDL.TableSpec.Options)
-	if comment, found := opt.options["comment"]; found {
-		o.sb.WriteString(fmt.Sprintf("COMMENT ON TABLE %v IS '%v';\n/\n", buildTableName(tableName), comment))
+	if len(o.Table.Comment) > 0 {
+		objects = append(objects, &DMDBObject{
+			Kind:  DMDBObjectComment,
+			Table: tableName,
+			SQL:   fmt.Sprintf("COMMENT ON TABLE %v IS %s;", buildTableName(tableName), quoteSQLString(o.Table.Comment)),
+		})
 	}
 
 	// table column comment
-	o.sb.WriteString(o.columnCommentsContainer.Render())
-	return o.sb.String()
+	for _, column := range columnComments {
+		objects = append(objects, &DMDBObject{
+			Kind:  DMDBObjectComment,
+			Table: tableName,
+			Name:  column.Name,
+			SQL:   (&dmdbColumnComment{tableName: tableName, Column: column}).Format(),
+		})
+	}
+
+	// generated column trigger
+	for _, trigger := range triggers {
+		if sql := trigger.Format(); len(sql) > 0 {
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectTrigger, Table: tableName, Name: trigger.columnName, SQL: sql})
+		}
+	}
+	return objects
 }
 
 type dmdbTableIndex struct {
-	tableName string
-	*sqlparser.IndexDefinition
+	conv  *DMDB
+	table *Table
+	*Index
 }
 
 func (t *dmdbTableIndex) Format() string {
-	var info = t.IndexDefinition.Info
-	var indexName = t.IndexDefinition.Info.Name.String()
+	var tableName = t.table.Name
 	var sb strings.Builder
 
+	for _, col := range t.Columns {
+		column := t.table.Column(col.Name)
+		if column == nil {
+			continue
+		}
+		if tableColumn, _ := newDMDBTableColumn(t.conv, t.table, column); !tableColumn.mapped() {
+			t.conv.diagnostics.Warnf(tableName, t.Name, "index skipped, column %s is skipped", col.Name)
+			return ""
+		}
+	}
+
+	switch t.Kind {
+	case IndexFulltext:
+		return t.formatFulltextIndex()
+	case IndexSpatial:
+		return t.formatSpatialIndex()
+	}
+
+	columns, ok := t.formatIndexColumns()
+	if !ok {
+		return ""
+	}
+
 	// 下面的indexName 会加上表名的原因是因为在达梦（pg等数据库）中，索引名称是数据库下唯一的
 	// 所以，如果sql查询语句中存在 force index的语法，应该去掉
-	if info.Primary {
+	switch t.Kind {
+	case IndexPrimary:
 		// 主键索引
 		_, _ = fmt.Fprintf(&sb, "ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (%s);",
-			buildTableName(t.tableName),
-			buildPKName(t.tableName, t.IndexDefinition.Columns),
-			buildIndexColumns(t.IndexDefinition.Columns, buildColumnName))
-	} else if info.Unique {
+			buildTableName(tableName),
+			buildPKName(tableName, t.ColumnNames()),
+			columns)
+	case IndexUnique:
 		// 唯一索引
 		_, _ = fmt.Fprintf(&sb, "CREATE UNIQUE INDEX %s ON %s(%s);",
-			buildIdxName("unq_", t.tableName, indexName),
-			buildTableName(t.tableName),
-			buildIndexColumns(t.IndexDefinition.Columns, buildColumnName))
-	} else {
+			buildIdxName("unq_", tableName, t.Name),
+			buildTableName(tableName),
+			columns)
+	default:
 		// 普通索引
 		_, _ = fmt.Fprintf(&sb, "CREATE INDEX %s ON %s(%s);",
-			buildIdxName("idx_", t.tableName, indexName),
-			buildTableName(t.tableName),
-			buildIndexColumns(t.IndexDefinition.Columns, buildColumnName))
+			buildIdxName("idx_", tableName, t.Name),
+			buildTableName(tableName),
+			columns)
 	}
 	return sb.String()
 }
 
+// formatFulltextIndex 全文索引转换为达梦的 CONTEXT INDEX，达梦的全文索引只支持单列
+func (t *dmdbTableIndex) formatFulltextIndex() string {
+	var tableName = t.table.Name
+
+	if t.conv.options.SkipFulltextIndex {
+		t.conv.diagnostics.Warnf(tableName, t.Name, "fulltext index skipped, disabled by options")
+		return ""
+	}
+	if len(t.Columns) != 1 {
+		t.conv.diagnostics.Warnf(tableName, t.Name,
+			"fulltext index skipped, DM context index does not support multiple columns (%s)",
+			strings.Join(t.ColumnNames(), ", "))
+		return ""
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "CREATE CONTEXT INDEX %s ON %s(%s)",
+		buildIdxName("idx_", tableName, t.Name),
+		buildTableName(tableName),
+		joinColumnNames(t.ColumnNames()))
+	// ngram 分词对应达梦的 CHINESE_VGRAM_LEXER
+	if t.Parser == "ngram" {
+		sb.WriteString(" LEXER CHINESE_VGRAM_LEXER")
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// formatSpatialIndex 空间索引转换为达梦的 SPATIAL INDEX，只支持单列
+func (t *dmdbTableIndex) formatSpatialIndex() string {
+	var tableName = t.table.Name
+
+	if t.conv.options.SkipSpatialIndex {
+		t.conv.diagnostics.Warnf(tableName, t.Name, "spatial index skipped, disabled by options")
+		return ""
+	}
+	if t.conv.options.SpatialType == SpatialWKB {
+		t.conv.diagnostics.Warnf(tableName, t.Name, "spatial index skipped, spatial columns are stored as WKB blob")
+		return ""
+	}
+	if len(t.Columns) != 1 {
+		t.conv.diagnostics.Warnf(tableName, t.Name,
+			"spatial index skipped, DM spatial index does not support multiple columns (%s)",
+			strings.Join(t.ColumnNames(), ", "))
+		return ""
+	}
+	return fmt.Sprintf("CREATE SPATIAL INDEX %s ON %s(%s);",
+		buildIdxName("idx_", tableName, t.Name),
+		buildTableName(tableName),
+		joinColumnNames(t.ColumnNames()))
+}
+
+// formatIndexColumns 输出索引列，按 DMDBOptions.PrefixIndex 处理前缀索引，
+// 按 DMDBOptions.CaseInsensitiveUnique 处理大小写不敏感的唯一索引，返回 false 表示该索引被跳过
+func (t *dmdbTableIndex) formatIndexColumns() (string, bool) {
+	var tableName = t.table.Name
+
+	var prefixColumns []string
+	for _, col := range t.Columns {
+		if col.Length != nil {
+			prefixColumns = append(prefixColumns, fmt.Sprintf("%s(%d)", col.Name, *col.Length))
+		}
+	}
+
+	strategy := t.conv.options.PrefixIndex
+	if len(prefixColumns) > 0 {
+		switch {
+		case t.Kind == IndexPrimary && strategy != PrefixIndexFullColumn:
+			// 主键约束只能建立在列上
+			t.conv.diagnostics.Warnf(tableName, t.Name,
+				"prefix columns %s of primary key can not be '%s', use full column instead",
+				strings.Join(prefixColumns, ", "), strategy)
+			strategy = PrefixIndexFullColumn
+		case strategy == PrefixIndexSkip:
+			t.conv.diagnostics.Warnf(tableName, t.Name,
+				"index skipped, DM does not support prefix columns %s", strings.Join(prefixColumns, ", "))
+			return "", false
+		case strategy == PrefixIndexSubstr:
+			t.conv.diagnostics.Infof(tableName, t.Name,
+				"prefix columns %s converted to SUBSTR function index", strings.Join(prefixColumns, ", "))
+		default:
+			t.conv.diagnostics.Infof(tableName, t.Name,
+				"prefix columns %s converted to full column index", strings.Join(prefixColumns, ", "))
+		}
+	}
+
+	upperColumns := t.caseInsensitiveUniqueColumns()
+	var exprs []string
+	for _, col := range t.Columns {
+		expr := buildColumnName(col.Name)
+		if strategy == PrefixIndexSubstr && col.Length != nil {
+			expr = fmt.Sprintf("SUBSTR(%s,1,%d)", expr, *col.Length)
+		}
+		if _, found := upperColumns[strings.ToLower(col.Name)]; found {
+			expr = fmt.Sprintf("UPPER(%s)", expr)
+		}
+		exprs = append(exprs, expr)
+	}
+	return strings.Join(exprs, ", "), true
+}
+
+// caseInsensitiveUniqueColumns 返回唯一索引中需要使用 UPPER() 的列
+// mysql 中 *_ci 排序规则的列比较时忽略大小写，而达梦的唯一索引区分大小写
+func (t *dmdbTableIndex) caseInsensitiveUniqueColumns() map[string]struct{} {
+	var tableName = t.table.Name
+
+	strategy := t.conv.options.CaseInsensitiveUnique
+	if !t.Unique() || strategy == CaseInsensitiveIgnore {
+		return nil
+	}
+
+	var names []string
+	var result = map[string]struct{}{}
+	for _, col := range t.Columns {
+		if collation, ok := t.caseInsensitiveCollation(col.Name); ok {
+			names = append(names, fmt.Sprintf("%s(%s)", col.Name, collation))
+			result[strings.ToLower(col.Name)] = struct{}{}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if strategy == CaseInsensitiveReport {
+		t.conv.diagnostics.Warnf(tableName, t.Name,
+			"unique index columns %s are case-insensitive in mysql but case-sensitive in DM", strings.Join(names, ", "))
+		return nil
+	}
+	if t.Kind == IndexPrimary {
+		// 主键约束只能建立在列上，无法使用 UPPER()
+		t.conv.diagnostics.Warnf(tableName, t.Name,
+			"primary key columns %s are case-insensitive in mysql but case-sensitive in DM, UPPER() can not be used in a primary key",
+			strings.Join(names, ", "))
+		return nil
+	}
+	t.conv.diagnostics.Infof(tableName, t.Name,
+		"case-insensitive columns %s converted to UPPER() function index", strings.Join(names, ", "))
+	return result
+}
+
+// caseInsensitiveCollation 判断字符串列是否使用大小写不敏感的排序规则，并返回该排序规则
+func (t *dmdbTableIndex) caseInsensitiveCollation(columnName string) (string, bool) {
+	column := t.table.Column(columnName)
+	if column == nil {
+		return "", false
+	}
+	if _, found := mysqlStringTypes[column.Type.Name]; !found {
+		return "", false
+	}
+	collation := columnCollation(column.Type, t.table.Options)
+	return collation, isCaseInsensitiveCollation(collation)
+}
+
 type dmdbTableColumn struct {
-	*sqlparser.ColumnDefinition
+	conv      *DMDB
+	table     *Table
+	generated *GeneratedColumn // 虚拟列，STORED 生成列由触发器维护时为 nil
+	*Column
+}
+
+// newDMDBTableColumn 返回列定义，STORED 生成列同时返回维护其值的触发器
+func newDMDBTableColumn(conv *DMDB, table *Table, column *Column) (*dmdbTableColumn, *dmdbGeneratedColumnTrigger) {
+	tableColumn := &dmdbTableColumn{
+		conv:   conv,
+		table:  table,
+		Column: column,
+	}
+	generated := column.Generated
+	if generated == nil {
+		return tableColumn, nil
+	}
+	if generated.Stored && !conv.options.StoredGeneratedAsVirtual {
+		// 达梦的虚拟列不存储数据，STORED 生成列使用普通列加触发器维护
+		return tableColumn, &dmdbGeneratedColumnTrigger{
+			conv:            conv,
+			tableName:       table.Name,
+			columnName:      column.Name,
+			GeneratedColumn: generated,
+		}
+	}
+	tableColumn.generated = generated
+	return tableColumn, nil
 }
 
 func (o *dmdbTableColumn) Format() string {
 	var sb = &strings.Builder{}
 
-	columnName := o.ColumnDefinition.Name.String()
-	columnType := o.ColumnDefinition.Type
+	columnName := o.Column.Name
+	columnType := o.Column.Type
 
 	// column name
 	sb.WriteString(buildColumnName(columnName))
 	sb.WriteByte(' ')
 
 	// column type name
-	if t, found := mysqlWithDMDatatypeMapping[columnType.Type]; found {
-		sb.WriteString(fmt.Sprintf("%s", t))
+	rule, _ := o.conv.options.TypeMapping.lookup(o.table.Name, columnName, columnType.Name)
+	if t, found := o.typeName(); found {
+		sb.WriteString(t)
 	} else {
-		log.Fatalf("the mysql column type '%v' mapping was not found", columnType.Type)
+		o.conv.diagnostics.Warnf(o.table.Name, columnName,
+			"column type '%s' has no DM mapping, column skipped, add it to the type mapping to convert it", columnType.Name)
 		return ""
 	}
 
 	// column type
-	o.formatColumnType(sb, columnName, columnType)
+	switch rule.Length {
+	case TypeLengthDefault:
+		o.formatColumnType(sb, columnName, columnType)
+	case TypeLengthNone:
+	case TypeLengthKeep:
+		if columnType.Length != nil && columnType.Scale != nil {
+			sb.WriteString(fmt.Sprintf("(%d,%d)", *columnType.Length, *columnType.Scale))
+		} else if columnType.Length != nil {
+			sb.WriteString(fmt.Sprintf("(%d)", *columnType.Length))
+		}
+	default:
+		if strings.HasPrefix(rule.Length, "(") {
+			sb.WriteString(rule.Length)
+		} else {
+			sb.WriteString(fmt.Sprintf("(%s)", rule.Length))
+		}
+	}
 	sb.WriteByte(' ')
 
+	// virtual column
+	if o.generated != nil {
+		if expr, err := convertMysqlExpr(o.generated.Expr, ""); err != nil {
+			o.conv.diagnostics.Warnf(o.table.Name, columnName,
+				"generated column expression '%s' can not be converted, created as plain column: %v", o.generated.Expr, err)
+		} else {
+			sb.WriteString(fmt.Sprintf("GENERATED ALWAYS AS (%s) VIRTUAL ", expr))
+		}
+	}
+
+	// column default
+	if o.generated == nil {
+		if def := o.formatDefault(); len(def) > 0 {
+			sb.WriteString(def)
+			sb.WriteByte(' ')
+		}
+	}
+
 	// column default(NULL or NOT NULL)
-	if columnType.NotNull {
+	if o.Column.NotNull {
 		sb.WriteString("NOT NULL")
 		sb.WriteByte(' ')
 	}
 	return sb.String()
 }
 
-func (o *dmdbTableColumn) formatColumnType(sb *strings.Builder, columnName string, columnType sqlparser.ColumnType) {
-	switch columnType.Type {
-	case "varchar", "varbinary", "char", "binary",
-		"tinytext":
+// typeName 返回列转换后的达梦类型名，不含长度，类型映射中的设置优先
+func (o *dmdbTableColumn) typeName() (string, bool) {
+	rule, _ := o.conv.options.TypeMapping.lookup(o.table.Name, o.Column.Name, o.Column.Type.Name)
+	if len(rule.Type) > 0 {
+		return rule.Type, true
+	}
+	return o.columnTypeName(o.Column.Name, o.Column.Type)
+}
+
+// mapped 判断列是否有对应的达梦类型，没有时该列被跳过
+func (o *dmdbTableColumn) mapped() bool {
+	_, found := o.typeName()
+	return found
+}
+
+// DMDBTypeName 返回列按 options 转换后的达梦类型名，没有对应的达梦类型时返回 false，
+// 用于在转换前检查不是由 mysql DDL 解析得到的表结构
+func DMDBTypeName(options DMDBOptions, table *Table, column *Column) (string, bool) {
+	tableColumn, _ := newDMDBTableColumn(&DMDB{options: options}, table, column)
+	return tableColumn.typeName()
+}
+
+// columnTypeName 返回列对应的达梦类型名，tinyint(1) 按 DMDBOptions.TinyintBool 转换，
+// 其他 tinyint 按是否 unsigned 转换为 SMALLINT 或 TINYINT
+func (o *dmdbTableColumn) columnTypeName(columnName string, columnType ColumnType) (string, bool) {
+	if columnType.Name == "tinyint" {
+		switch {
+		case o.isBoolColumn(columnName, columnType):
+			return o.conv.options.tinyintBool(o.table.Name, columnName).String(), true
+		case columnType.Unsigned:
+			return "smallint", true
+		default:
+			return "tinyint", true
+		}
+	}
+	if isSpatialType(columnType.Name) && o.conv.options.SpatialType == SpatialWKB {
+		return "blob", true
+	}
+	switch columnType.Name {
+	case "tinytext":
+		if o.conv.options.TextAsVarchar {
+			return "varchar2", true
+		}
+	case "float", "double", "real":
+		// float(m,d)、double(m,d) 按定点数处理
+		if columnType.Scale != nil {
+			return "number", true
+		}
+	case "bit":
+		// BIT(n) 按字节存储为 BINARY
+		if columnType.LengthOr(0) > 1 {
+			return "binary", true
+		}
+	case "varbinary":
+		if columnType.LengthOr(0) > dmdbMaxVarcharBytes {
+			return "longvarbinary", true
+		}
+	}
+	t, found := mysqlWithDMDatatypeMapping[columnType.Name]
+	return t, found
+}
+
+// isBoolColumn 判断列是否转换为达梦的 BIT/BOOLEAN
+func (o *dmdbTableColumn) isBoolColumn(columnName string, columnType ColumnType) bool {
+	switch columnType.Name {
+	case "bool", "boolean":
+		return true
+	case "bit":
+		return columnType.LengthOr(0) <= 1
+	case "tinyint":
+		return columnType.LengthOr(0) == 1 &&
+			o.conv.options.tinyintBool(o.table.Name, columnName) != TinyintBoolNumber
+	}
+	return false
+}
+
+func (o *dmdbTableColumn) formatColumnType(sb *strings.Builder, columnName string, columnType ColumnType) {
+	switch columnType.Name {
+	case "varchar", "char":
 		if columnType.Length != nil {
-			num, err := strconv.ParseInt(string(columnType.Length.Val), 0, 64)
-			if err != nil {
-				log.Fatalf("invalid length val: %v %v", columnType.Length.Type, columnType.Length.Val)
-			}
+			sb.WriteString(o.formatCharLength(columnName, columnType))
+		}
+	case "bit":
+		if bits := columnType.LengthOr(0); bits > 1 {
+			sb.WriteString(fmt.Sprintf("(%d)", (bits+7)/8))
+		}
+	case "varbinary":
+		if num := columnType.LengthOr(0); num > dmdbMaxVarcharBytes {
+			o.conv.diagnostics.Infof(o.table.Name, columnName,
+				"varbinary(%d) exceeds DM max varbinary length %d, converted to longvarbinary", num, dmdbMaxVarcharBytes)
+		} else if num > 0 {
+			sb.WriteString(fmt.Sprintf("(%d)", num))
+		}
+	case "binary":
+		if num := columnType.LengthOr(0); num > 0 {
 			sb.WriteString(fmt.Sprintf("(%d)", num))
 		}
-	case "blob", "tinyblob":
+	case "tinytext":
+		if o.conv.options.TextAsVarchar {
+			// tinytext 最多 255 字节，即最多 255 个字符
+			sb.WriteString(o.formatLength(columnName, columnType, 255))
+		}
+	case "text":
+		if o.conv.options.TextAsVarchar {
+			o.conv.diagnostics.Infof(o.table.Name, columnName,
+				"text holds up to 65535 bytes, exceeds DM max varchar length %d, converted to TEXT", dmdbMaxVarcharBytes)
+		}
+	case "tinyblob":
 		sb.WriteString("(255)")
-	case "mediumblob":
-		sb.WriteString("(16777215)")
-	case "longblob":
-		sb.WriteString(fmt.Sprintf("(%d)", math.MaxInt32))
-	case "decimal", "dec", "float", "double":
+	case "decimal", "numeric", "dec":
+		// mysql 中 decimal 等同于 decimal(10,0)，decimal(m) 等同于 decimal(m,0)
+		precision, scale := columnType.LengthOr(10), 0
+		if columnType.Scale != nil {
+			scale = *columnType.Scale
+		}
+		sb.WriteString(fmt.Sprintf("(%d,%d)", precision, scale))
+		sb.WriteString(o.formatUnsignedCheck(columnName, columnType))
+	case "float", "double", "real":
 		if columnType.Length != nil && columnType.Scale != nil {
-			sb.WriteString(fmt.Sprintf("(%v,%v)", columnType.Length, columnType.Scale))
-		} else if columnType.Length != nil {
-			sb.WriteString(fmt.Sprintf("(%v,0)", columnType.Length))
+			sb.WriteString(fmt.Sprintf("(%d,%d)", *columnType.Length, *columnType.Scale))
 		}
+		sb.WriteString(o.formatUnsignedCheck(columnName, columnType))
 	case "enum", "set":
-		sb.WriteString(fmt.Sprintf("(64) CHECK(%s IN (%s))", columnName, strings.Join(columnType.EnumValues, ", ")))
+		sb.WriteString(o.formatEnumColumn(columnName, columnType))
 	case "json":
-		// 改约束只创建一次
-		jsonConstraintOnce.Do(func() {
-			sb.WriteString(" CONSTRAINT ensure_json")
-		})
-		sb.WriteString(fmt.Sprintf(" CHECK (%s IS JSON)", columnName))
-
-	case "text", "mediumtext", "longtext",
+		sb.WriteString(fmt.Sprintf(" CONSTRAINT %s CHECK (%s IS JSON)",
+			buildIdxName("ck_", o.table.Name, columnName+"_json"), buildColumnName(columnName)))
+
+	case "geometry", "point", "linestring", "polygon",
+		"multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		sb.WriteString(o.formatSpatialColumn(columnName))
+	case "mediumtext", "longtext",
+		"blob", "mediumblob", "longblob",
 		"boolean", "bool",
 		"date", "datetime",
-		"int", "integer", "bigint", "bit", "tinyint", "smallint", "mediumint":
+		"int", "integer", "bigint", "tinyint", "smallint", "mediumint":
 		// ignore
 	default:
-		log.Fatalf("undeliverable date type '%v'", columnType)
+		// 类型映射中设置的其他类型不输出长度
+		if columnType.Length != nil {
+			o.conv.diagnostics.Infof(o.table.Name, columnName,
+				"length of column type '%s' not converted, set the length in the type mapping to keep it", columnType.Name)
+		}
 	}
 }
 
+// dmdbGeneratedColumnTrigger 维护 STORED 生成列的触发器
+type dmdbGeneratedColumnTrigger struct {
+	conv       *DMDB
+	tableName  string
+	columnName string
+	*GeneratedColumn
+}
+
+func (t *dmdbGeneratedColumnTrigger) Format() string {
+	expr, err := convertMysqlExpr(t.Expr, ":NEW.")
+	if err != nil {
+		t.conv.diagnostics.Warnf(t.tableName, t.columnName,
+			"generated column expression '%s' can not be converted, created as plain column: %v", t.Expr, err)
+		return ""
+	}
+	triggerName := buildIdxName("trg_", t.tableName, t.columnName)
+	t.conv.diagnostics.Infof(t.tableName, t.columnName, "STORED generated column maintained by trigger %s", triggerName)
+	return fmt.Sprintf(`CREATE OR REPLACE TRIGGER %s
+BEFORE INSERT OR UPDATE ON %s
+FOR EACH ROW
+BEGIN
+   :NEW.%s := %s;
+END;`, triggerName, buildTableName(t.tableName), buildColumnName(t.columnName), expr)
+}
+
+// 达梦 8K 页大小下 VARCHAR 的最大字节数
+const dmdbMaxVarcharBytes = 8188
+
+// formatCharLength 多字节字符集的列按 DMDBOptions.CharLength 输出字符长度或换算后的字节长度
+func (o *dmdbTableColumn) formatCharLength(columnName string, columnType ColumnType) string {
+	return o.formatLength(columnName, columnType, columnType.LengthOr(0))
+}
+
+// formatLength 输出长度为 num 个字符的列的长度
+func (o *dmdbTableColumn) formatLength(columnName string, columnType ColumnType, num int) string {
+	charset := o.columnCharset(columnType)
+	maxBytes := charsetMaxBytes(charset, o.conv.targetCharset())
+	if maxBytes == 1 {
+		return fmt.Sprintf("(%d)", num)
+	}
+	if o.conv.options.CharLength == CharLengthByte {
+		bytes := num * maxBytes
+		if bytes > dmdbMaxVarcharBytes {
+			o.conv.diagnostics.Warnf(o.table.Name, columnName,
+				"%s(%d) in %s needs %d bytes, exceeds DM max varchar length %d", columnType.Name, num, charset, bytes, dmdbMaxVarcharBytes)
+		}
+		return fmt.Sprintf("(%d)", bytes)
+	}
+	return fmt.Sprintf("(%d CHAR)", num)
+}
+
+// columnCharset 返回列的字符集，依次使用列的字符集、列的排序规则、表的默认字符集
+func (o *dmdbTableColumn) columnCharset(columnType ColumnType) string {
+	if len(columnType.Charset) > 0 {
+		return columnType.Charset
+	}
+	if len(columnType.Collate) > 0 {
+		return collationCharset(columnType.Collate)
+	}
+	if o.table.Options != nil {
+		return o.table.Options.Charset()
+	}
+	return ""
+}
+
 type dmdbColumnComment struct {
 	tableName string
-	*sqlparser.ColumnDefinition
+	*Column
 }
 
 func (d *dmdbColumnComment) Format() string {
-	if d.ColumnDefinition.Type.Comment != nil {
-		columnName := buildColumnName(d.ColumnDefinition.Name.String())
-		return fmt.Sprintf(`COMMENT ON COLUMN %s.%s IS '%s';`,
-			buildTableName(d.tableName), columnName, d.ColumnDefinition.Type.Comment.Val)
+	if len(d.Comment) > 0 {
+		return fmt.Sprintf(`COMMENT ON COLUMN %s.%s IS %s;`,
+			buildTableName(d.tableName), buildColumnName(d.Name), quoteSQLString(d.Comment))
 	}
 	return ""
 }
 
+type dmdbForeignKey struct {
+	conv *DMDB
+	*ForeignKey
+}
+
+func (d *dmdbForeignKey) Format() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s)",
+		buildTableName(d.Table),
+		buildFKName(d.Table, d.Name),
+		joinColumnNames(d.Columns),
+		buildTableName(d.RefTable),
+		joinColumnNames(d.RefColumns))
+
+	switch d.OnDelete {
+	case "CASCADE", "SET NULL":
+		sb.WriteString(" ON DELETE ")
+		sb.WriteString(d.OnDelete)
+	case "SET DEFAULT":
+		d.conv.diagnostics.Warnf(d.Table, d.Name, "ON DELETE SET DEFAULT is not supported by DM, ignored")
+	}
+	// 达梦的外键不支持 ON UPDATE，RESTRICT 和 NO ACTION 与默认行为一致
+	switch d.OnUpdate {
+	case "", "RESTRICT", "NO ACTION":
+	default:
+		d.conv.diagnostics.Warnf(d.Table, d.Name, "ON UPDATE %s is not supported by DM, ignored", d.OnUpdate)
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+type dmdbDropForeignKey struct {
+	tableName string
+	name      string
+}
+
+func (d *dmdbDropForeignKey) Format() string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", buildTableName(d.tableName), buildFKName(d.tableName, d.name))
+}
+
 type dmdbDropTableIfExists struct {
-	*sqlparser.DDL
+	*DropTable
 }
 
 func (d *dmdbDropTableIfExists) Format() string {
@@ -276,9 +880,9 @@ func (d *dmdbDropTableIfExists) Format() string {
    EXECUTE IMMEDIATE 'DROP TABLE %s';
 EXCEPTION
    WHEN OTHERS THEN NULL;
-END;`, buildTableName(d.Table.Name.String()))
+END;`, buildTableName(d.Name))
 	}
-	return ""
+	return fmt.Sprintf("DROP TABLE %s;", buildTableName(d.Name))
 }
 
 func buildColumnName(columnName string) string {
@@ -294,3 +898,77 @@ func buildTableName(tableName string) string {
 	}
 	return tableName
 }
+
+// formatEnumColumn ENUM/SET 转换为 varchar2 加命名的 CHECK 约束，长度取最长的取值（SET 为全部取值以逗号连接后的长度），
+// SET 的约束校验逗号分隔的每一个成员
+func (o *dmdbTableColumn) formatEnumColumn(columnName string, columnType ColumnType) string {
+	values := columnType.Values
+
+	var num int
+	for _, v := range values {
+		if n := utf8.RuneCountInString(v); columnType.Name == "enum" && n > num {
+			num = n
+		} else if columnType.Name == "set" {
+			num += n
+		}
+	}
+	if columnType.Name == "set" && len(values) > 1 {
+		num += len(values) - 1
+	}
+	if num == 0 {
+		num = 1
+	}
+
+	constraintName := buildIdxName("ck_", o.table.Name, columnName)
+	quotedName := buildColumnName(columnName)
+	if columnType.Name == "set" {
+		var members []string
+		for _, v := range values {
+			members = append(members, regexp.QuoteMeta(v))
+		}
+		member := "(" + strings.Join(members, "|") + ")"
+		pattern := fmt.Sprintf("^(%s(,%s)*)?$", member, member)
+		return fmt.Sprintf("%s CONSTRAINT %s CHECK(REGEXP_LIKE(%s, %s))",
+			o.formatLength(columnName, columnType, num), constraintName, quotedName, quoteSQLString(pattern))
+	}
+
+	var quoted []string
+	for _, v := range values {
+		quoted = append(quoted, quoteSQLString(v))
+	}
+	return fmt.Sprintf("%s CONSTRAINT %s CHECK(%s IN (%s))",
+		o.formatLength(columnName, columnType, num), constraintName, quotedName, strings.Join(quoted, ", "))
+}
+
+func isSpatialType(typeName string) bool {
+	switch typeName {
+	case "geometry", "point", "linestring", "polygon",
+		"multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return true
+	}
+	return false
+}
+
+// formatSpatialColumn 输出空间类型列的 SRID 约束，按 WKB 存储时无法校验 SRID，只给出提示
+func (o *dmdbTableColumn) formatSpatialColumn(columnName string) string {
+	if o.conv.options.SpatialType == SpatialWKB {
+		if o.SRID > 0 {
+			o.conv.diagnostics.Infof(o.table.Name, columnName, "stored as WKB blob, SRID %d is not checked", o.SRID)
+		}
+		return ""
+	}
+	if o.SRID == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" CONSTRAINT %s CHECK (DMGEO.ST_SRID(%s) = %d)",
+		buildIdxName("ck_", o.table.Name, columnName+"_srid"), buildColumnName(columnName), o.SRID)
+}
+
+// formatUnsignedCheck unsigned 的 decimal、float、double 列不允许负数，使用 CHECK 约束保证
+func (o *dmdbTableColumn) formatUnsignedCheck(columnName string, columnType ColumnType) string {
+	if !columnType.Unsigned {
+		return ""
+	}
+	return fmt.Sprintf(" CONSTRAINT %s CHECK (%s >= 0)",
+		buildIdxName("ck_", o.table.Name, columnName+"_unsigned"), buildColumnName(columnName))
+}
@@ -0,0 +1,131 @@
+package convertor
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// formatDefault 输出布尔列及二进制列的 DEFAULT 子句，其他列的默认值不输出。无法转换的默认值会被忽略并给出提示
+func (o *dmdbTableColumn) formatDefault() string {
+	columnName, columnType := o.Column.Name, o.Column.Type
+	def := o.Column.Default
+	if def == nil {
+		return ""
+	}
+	if o.isBoolColumn(columnName, columnType) {
+		return o.formatBoolDefault(columnName, def)
+	}
+	if size := binaryColumnSize(columnType); size >= 0 {
+		return o.formatBinaryDefault(columnName, def, size)
+	}
+	return ""
+}
+
+// convertsDefault 列的默认值是否输出到 DEFAULT 子句
+func (o *dmdbTableColumn) convertsDefault() bool {
+	return o.isBoolColumn(o.Column.Name, o.Column.Type) || binaryColumnSize(o.Column.Type) >= 0
+}
+
+// formatBoolDefault 布尔列的默认值转换为 0/1
+func (o *dmdbTableColumn) formatBoolDefault(columnName string, def *DefaultValue) string {
+	if def.Kind == DefaultNull {
+		return "DEFAULT NULL"
+	}
+	val := strings.TrimSpace(def.Value)
+
+	base := 10
+	if def.Kind == DefaultBits {
+		base = 2
+	}
+	num, err := strconv.ParseInt(val, base, 64)
+	if err != nil {
+		o.conv.diagnostics.Warnf(o.table.Name, columnName, "default value '%s' of boolean column can not be converted, skipped", def.Value)
+		return ""
+	}
+	if num != 0 && num != 1 {
+		o.conv.diagnostics.Infof(o.table.Name, columnName, "default value %d of boolean column converted to 1", num)
+		num = 1
+	}
+	return "DEFAULT " + strconv.FormatInt(num, 10)
+}
+
+// binaryColumnSize 返回转换为 BINARY/VARBINARY 的列的字节数，bit(n) 为 n/8 向上取整，binary(n) 为 n，
+// varbinary 返回 0 表示按默认值的长度输出，其他类型返回 -1
+func binaryColumnSize(columnType ColumnType) int {
+	switch columnType.Name {
+	case "bit":
+		if bits := columnType.LengthOr(0); bits > 1 {
+			return (bits + 7) / 8
+		}
+	case "binary":
+		return columnType.LengthOr(0)
+	case "varbinary":
+		return 0
+	}
+	return -1
+}
+
+// formatBinaryDefault 二进制列的默认值输出为达梦的十六进制字面量。bit 列的默认值按数值输出，例如 b'0101' 输出为 0x05；
+// binary/varbinary 列与 mysql 一致按字节输出，binary(n) 在右侧补 0x00 至 n 个字节
+func (o *dmdbTableColumn) formatBinaryDefault(columnName string, def *DefaultValue, size int) string {
+	switch def.Kind {
+	case DefaultNull:
+		return "DEFAULT NULL"
+	case DefaultCurrentTimestamp:
+		o.conv.diagnostics.Warnf(o.table.Name, columnName, "default value %s of binary column can not be converted, skipped", def.Value)
+		return ""
+	}
+	if o.Column.Type.Name != "bit" {
+		return o.formatBytesDefault(columnName, def, size)
+	}
+
+	var num = new(big.Int)
+	var ok bool
+	switch def.Kind {
+	case DefaultBits:
+		_, ok = num.SetString(def.Value, 2)
+	case DefaultNumber, DefaultString:
+		_, ok = num.SetString(def.Value, 10)
+	}
+	if !ok {
+		o.conv.diagnostics.Warnf(o.table.Name, columnName, "default value '%s' of binary column can not be converted, skipped", def.Value)
+		return ""
+	}
+
+	hex := num.Text(16)
+	if len(hex) < size*2 {
+		hex = strings.Repeat("0", size*2-len(hex)) + hex
+	}
+	if len(hex)%2 == 1 {
+		hex = "0" + hex
+	}
+	return "DEFAULT 0x" + strings.ToUpper(hex)
+}
+
+// formatBytesDefault binary/varbinary 列的默认值按字节输出，b'...'、x'...' 按字面量的字节，字符串及数字按其文本
+func (o *dmdbTableColumn) formatBytesDefault(columnName string, def *DefaultValue, size int) string {
+	var hex string
+	if def.Kind == DefaultBits {
+		num, ok := new(big.Int).SetString(def.Value, 2)
+		if !ok {
+			o.conv.diagnostics.Warnf(o.table.Name, columnName, "default value '%s' of binary column can not be converted, skipped", def.Value)
+			return ""
+		}
+		hex = num.Text(16)
+		if n := (len(def.Value) + 7) / 8 * 2; len(hex) < n {
+			hex = strings.Repeat("0", n-len(hex)) + hex
+		}
+	} else {
+		hex = fmt.Sprintf("%X", def.Value)
+	}
+	if len(hex) < size*2 {
+		hex += strings.Repeat("0", size*2-len(hex))
+	}
+	if len(hex) == 0 {
+		o.conv.diagnostics.Warnf(o.table.Name, columnName, "empty default value of binary column can not be converted, skipped")
+		return ""
+	}
+	return "DEFAULT 0x" + strings.ToUpper(hex)
+}
@@ -0,0 +1,241 @@
+package convertor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var _ Element = (*dmdbDropTable)(nil)
+var _ Element = (*dmdbAddColumn)(nil)
+var _ Element = (*dmdbDropColumn)(nil)
+var _ Element = (*dmdbModifyColumn)(nil)
+var _ Element = (*dmdbComment)(nil)
+var _ Element = (*dmdbDropIndex)(nil)
+
+var dmdbCheckConstraintRegexp = regexp.MustCompile(`CONSTRAINT (\S+) CHECK`)
+
+// DMDBDiff 比较新旧两份 mysql DDL，输出将已按旧 DDL 转换的达梦数据库迁移到新结构的语句
+type DMDBDiff struct {
+	*DMDB
+	oldSQL string
+}
+
+func NewDMDBDiff(oldSQL, newSQL string, options DMDBOptions) *DMDBDiff {
+	return &DMDBDiff{DMDB: NewDMDBWithOptions(newSQL, options), oldSQL: oldSQL}
+}
+
+func (o *DMDBDiff) Exec() (string, error) {
+	o.resetDiagnostics()
+	// 旧 DDL 的提示在转换时已经给出过，这里不再重复
+	var oldDiagnostics Diagnostics
+	oldSchema := parseMysqlSchema(o.oldSQL, &oldDiagnostics)
+	newSchema := parseMysqlSchema(o.sql, &o.diagnostics)
+	diff := DiffSchema(oldSchema, newSchema)
+	for _, name := range oldSchema.SkippedTables {
+		o.diagnostics.Warnf(name, "", "table skipped when parsing the old DDL, its changes are not converted")
+	}
+
+	var drops = NewContainerWithSuffix("\n/\n", true)
+	var container = NewContainerWithSuffix("\n/\n", true)
+	var foreignKeys []*ForeignKey
+
+	for _, tableDiff := range diff.AlterTables {
+		for _, fk := range tableDiff.DropForeignKeys {
+			drops.Append(&dmdbDropForeignKey{tableName: fk.Table, name: fk.Name})
+		}
+		for _, index := range tableDiff.DropIndexes {
+			drops.Append(&dmdbDropIndex{conv: o.DMDB, table: tableDiff.Old, Index: index})
+		}
+	}
+	for _, table := range diff.DropTables {
+		drops.Append(&dmdbDropTable{name: table.Name})
+	}
+	for _, table := range diff.CreateTables {
+		container.Append(newDMDBCreateTable(o.DMDB, table))
+		foreignKeys = append(foreignKeys, table.ForeignKeys...)
+	}
+	for _, tableDiff := range diff.AlterTables {
+		o.alterTable(container, tableDiff)
+		foreignKeys = append(foreignKeys, tableDiff.AddForeignKeys...)
+	}
+	for _, fk := range foreignKeys {
+		container.Append(&dmdbForeignKey{conv: o.DMDB, ForeignKey: fk})
+	}
+
+	if dropped := drops.Render(); len(dropped) > 0 {
+		if created := container.Render(); len(created) > 0 {
+			return dropped + "\n/\n" + created, nil
+		}
+		return dropped, nil
+	}
+	return container.Render(), nil
+}
+
+func (o *DMDBDiff) alterTable(container *Container, diff *TableDiff) {
+	tableName := diff.New.Name
+
+	for _, option := range diff.Options {
+		o.diagnostics.Infof(tableName, "", "table option %s changed from '%s' to '%s', not converted", option.Key, option.Old, option.New)
+	}
+	if diff.PartitionChanged() {
+		o.diagnostics.Warnf(tableName, "", "partition changes are not converted")
+	}
+
+	for _, column := range diff.DropColumns {
+		container.Append(&dmdbDropColumn{conv: o.DMDB, table: diff.Old, Column: column})
+	}
+	for _, column := range diff.AddColumns {
+		tableColumn, trigger := newDMDBTableColumn(o.DMDB, diff.New, column)
+		if column.NotNull && column.Default != nil && column.Default.Kind != DefaultNull && !tableColumn.convertsDefault() {
+			o.diagnostics.Warnf(tableName, column.Name,
+				"default value '%s' is not converted, adding the NOT NULL column fails if the table has rows", column.Default.Value)
+		}
+		container.Append(&dmdbAddColumn{tableColumn})
+		if !tableColumn.mapped() {
+			continue
+		}
+		if len(column.Comment) > 0 {
+			container.Append(&dmdbColumnComment{tableName: tableName, Column: column})
+		}
+		if trigger != nil {
+			container.Append(trigger)
+		}
+	}
+	for _, columnDiff := range diff.ModifyColumns {
+		column := columnDiff.New
+		container.Append(&dmdbModifyColumn{conv: o.DMDB, diff: diff, ColumnDiff: columnDiff})
+		if _, mapped := DMDBTypeName(o.options, diff.New, column); mapped && columnDiff.CommentChanged() {
+			container.Append(&dmdbComment{
+				target:  fmt.Sprintf("COLUMN %s.%s", buildTableName(tableName), buildColumnName(column.Name)),
+				comment: column.Comment,
+			})
+		}
+	}
+	if diff.CommentChanged() {
+		container.Append(&dmdbComment{target: "TABLE " + buildTableName(tableName), comment: diff.New.Comment})
+	}
+	for _, index := range diff.AddIndexes {
+		container.Append(&dmdbTableIndex{conv: o.DMDB, table: diff.New, Index: index})
+	}
+}
+
+type dmdbDropTable struct {
+	name string
+}
+
+func (d *dmdbDropTable) Format() string {
+	return fmt.Sprintf("DROP TABLE %s;", buildTableName(d.name))
+}
+
+type dmdbAddColumn struct {
+	*dmdbTableColumn
+}
+
+func (d *dmdbAddColumn) Format() string {
+	definition := strings.TrimSpace(d.dmdbTableColumn.Format())
+	if len(definition) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD %s;", buildTableName(d.table.Name), definition)
+}
+
+type dmdbDropColumn struct {
+	conv  *DMDB
+	table *Table
+	*Column
+}
+
+func (d *dmdbDropColumn) Format() string {
+	var statements []string
+	if _, trigger := newDMDBTableColumn(d.conv, d.table, d.Column); trigger != nil {
+		statements = append(statements, fmt.Sprintf("DROP TRIGGER %s;", buildIdxName("trg_", d.table.Name, d.Name)))
+	}
+	statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", buildTableName(d.table.Name), buildColumnName(d.Name)))
+	return strings.Join(statements, "\n")
+}
+
+// dmdbModifyColumn 同名列转换后的定义不同时输出 MODIFY，列上 myto 生成的 CHECK 约束先删除再随新定义重建
+type dmdbModifyColumn struct {
+	conv *DMDB
+	diff *TableDiff
+	*ColumnDiff
+}
+
+func (d *dmdbModifyColumn) Format() string {
+	// 旧定义只用于比较，转换时产生的提示已在转换旧 DDL 时给出
+	scratch := &DMDB{options: d.conv.options}
+	oldColumn, oldTrigger := newDMDBTableColumn(scratch, d.diff.Old, d.Old)
+	oldDefinition := strings.TrimSpace(oldColumn.Format())
+	scratchColumn, _ := newDMDBTableColumn(scratch, d.diff.New, d.New)
+	newDefinition := strings.TrimSpace(scratchColumn.Format())
+
+	var statements []string
+	newColumn, newTrigger := newDMDBTableColumn(d.conv, d.diff.New, d.New)
+	switch {
+	case len(newDefinition) == 0:
+		// 没有对应的达梦类型，列被跳过，旧列同样被跳过时不再重复提示
+		if len(oldDefinition) > 0 {
+			newColumn.Format()
+		}
+		return ""
+	case len(oldDefinition) == 0:
+		// 旧列没有对应的达梦类型而被跳过，按新增列处理
+		statements = append(statements, (&dmdbAddColumn{newColumn}).Format())
+	case oldDefinition != newDefinition:
+		tableName := buildTableName(d.diff.New.Name)
+		for _, matches := range dmdbCheckConstraintRegexp.FindAllStringSubmatch(oldDefinition, -1) {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", tableName, matches[1]))
+		}
+		definition := strings.TrimSpace(newColumn.Format())
+		if d.Old.NotNull && !d.New.NotNull {
+			definition += " NULL"
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY %s;", tableName, definition))
+	}
+
+	switch {
+	case newTrigger != nil && (oldTrigger == nil || d.Old.Generated.Expr != d.New.Generated.Expr):
+		if trigger := newTrigger.Format(); len(trigger) > 0 {
+			statements = append(statements, trigger)
+		}
+	case newTrigger == nil && oldTrigger != nil:
+		statements = append(statements, fmt.Sprintf("DROP TRIGGER %s;", buildIdxName("trg_", d.diff.Old.Name, d.Old.Name)))
+	}
+	return strings.Join(statements, "\n")
+}
+
+// dmdbComment 修改或清除表、列的注释
+type dmdbComment struct {
+	target  string
+	comment string
+}
+
+func (d *dmdbComment) Format() string {
+	return fmt.Sprintf("COMMENT ON %s IS %s;", d.target, quoteSQLString(d.comment))
+}
+
+// dmdbDropIndex 删除旧 DDL 中转换得到的索引，转换时被跳过的索引不输出
+type dmdbDropIndex struct {
+	conv  *DMDB
+	table *Table
+	*Index
+}
+
+func (d *dmdbDropIndex) Format() string {
+	scratch := &DMDB{options: d.conv.options}
+	if len((&dmdbTableIndex{conv: scratch, table: d.table, Index: d.Index}).Format()) == 0 {
+		return ""
+	}
+
+	tableName := d.table.Name
+	switch d.Kind {
+	case IndexPrimary:
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", buildTableName(tableName), buildPKName(tableName, d.ColumnNames()))
+	case IndexUnique:
+		return fmt.Sprintf("DROP INDEX %s;", buildIdxName("unq_", tableName, d.Name))
+	case IndexFulltext:
+		return fmt.Sprintf("DROP CONTEXT INDEX %s ON %s;", buildIdxName("idx_", tableName, d.Name), buildTableName(tableName))
+	}
+	return fmt.Sprintf("DROP INDEX %s;", buildIdxName("idx_", tableName, d.Name))
+}
@@ -0,0 +1,136 @@
+package convertor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDMDBDiff(t *testing.T) {
+	oldSQL := "CREATE TABLE `reply` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`title` varchar(64) NOT NULL COMMENT '标题',\n" +
+		"`kind` enum('a','b') DEFAULT NULL,\n" +
+		"`legacy` int DEFAULT NULL,\n" +
+		"`body` text,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"KEY `idx_title` (`title`),\n" +
+		"KEY `idx_legacy` (`legacy`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='回复';\n" +
+		"CREATE TABLE `obsolete` (`id` int NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB;"
+	newSQL := "CREATE TABLE `reply` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`title` varchar(128) DEFAULT NULL COMMENT '标题',\n" +
+		"`kind` enum('a','b','c') DEFAULT NULL,\n" +
+		"`body` text COMMENT '正文',\n" +
+		"`draft_uuid` varchar(8) NOT NULL DEFAULT '' COMMENT '草稿',\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"UNIQUE KEY `idx_title` (`title`),\n" +
+		"KEY `idx_draft` (`draft_uuid`),\n" +
+		"CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=DYNAMIC COMMENT='回复表';\n" +
+		"CREATE TABLE `draft` (`uuid` varchar(8) NOT NULL, PRIMARY KEY (`uuid`)) ENGINE=InnoDB;"
+
+	o := NewDMDBDiff(oldSQL, newSQL, DMDBOptions{})
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "DROP INDEX idx_reply_idx_title;\n/\nDROP INDEX idx_reply_idx_legacy;\n/\nDROP TABLE obsolete;\n/\nCREATE TABLE draft (")
+	assert.Contains(t, got, "ALTER TABLE reply DROP COLUMN legacy;")
+	assert.Contains(t, got, "ALTER TABLE reply ADD draft_uuid varchar2(8 CHAR) NOT NULL;\n/\nCOMMENT ON COLUMN reply.draft_uuid IS '草稿';")
+	assert.Contains(t, got, "ALTER TABLE reply MODIFY title varchar2(128 CHAR) NULL;")
+	assert.Contains(t, got, "ALTER TABLE reply DROP CONSTRAINT ck_reply_kind;\nALTER TABLE reply MODIFY kind varchar2(1 CHAR) CONSTRAINT ck_reply_kind CHECK(kind IN ('a', 'b', 'c'));")
+	assert.Contains(t, got, "COMMENT ON COLUMN reply.body IS '正文';")
+	assert.Contains(t, got, "COMMENT ON TABLE reply IS '回复表';")
+	assert.Contains(t, got, "CREATE UNIQUE INDEX unq_reply_idx_title ON reply(UPPER(title));")
+	assert.Contains(t, got, "CREATE INDEX idx_reply_idx_draft ON reply(draft_uuid);")
+	assert.True(t, strings.HasSuffix(got, "ALTER TABLE reply ADD CONSTRAINT fk_reply_reply_draft FOREIGN KEY (draft_uuid) REFERENCES draft(uuid);"))
+	assert.NotContains(t, got, "MODIFY id")
+	assert.NotContains(t, got, "pk_reply")
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Contains(t, messages, "[INFO] reply: table option row_format changed from '' to 'DYNAMIC', not converted")
+	assert.Contains(t, messages, "[WARN] reply.draft_uuid: default value '' is not converted, adding the NOT NULL column fails if the table has rows")
+}
+
+func TestDiffSchema(t *testing.T) {
+	oldSchema := parseMysqlSchema("CREATE TABLE `t` (`id` int NOT NULL, `name` varchar(8) COMMENT 'a', PRIMARY KEY (`id`));", &Diagnostics{})
+	newSchema := parseMysqlSchema("CREATE TABLE `T` (`ID` int NOT NULL, `name` varchar(8) COMMENT 'b', PRIMARY KEY (`ID`));", &Diagnostics{})
+
+	diff := DiffSchema(oldSchema, newSchema)
+	assert.Empty(t, diff.CreateTables)
+	assert.Empty(t, diff.DropTables)
+	assert.Len(t, diff.AlterTables, 1)
+
+	tableDiff := diff.AlterTables[0]
+	assert.Len(t, tableDiff.ModifyColumns, 1)
+	assert.False(t, tableDiff.ModifyColumns[0].DefinitionChanged())
+	assert.True(t, tableDiff.ModifyColumns[0].CommentChanged())
+	assert.Empty(t, tableDiff.DropIndexes)
+	assert.Empty(t, tableDiff.AddIndexes)
+}
+
+func TestDMDBDiff_SkippedTable(t *testing.T) {
+	tableA := "CREATE TABLE `a` (`id` int NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB;\n"
+	tableB := "CREATE TABLE `b` (`x` int NOT NULL) ENGINE=InnoDB;"
+	checkB := "CREATE TABLE `b` (`x` int NOT NULL, CONSTRAINT `c` CHECK (`x` > 0)) ENGINE=InnoDB;"
+
+	// 新 DDL 中 b 的 CHECK 约束无法解析，b 不能被删除
+	o := NewDMDBDiff(tableA+tableB, tableA+checkB, DMDBOptions{})
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.NotContains(t, got, "DROP TABLE b")
+	assert.Equal(t, "[WARN] b: unsupported CREATE statement skipped", o.Diagnostics()[0].String())
+
+	// 旧 DDL 中的 b 被跳过时，b 也不能被新建
+	o = NewDMDBDiff(tableA+checkB, tableA+tableB, DMDBOptions{})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.NotContains(t, got, "CREATE TABLE b")
+	assert.Equal(t, "[WARN] b: table skipped when parsing the old DDL, its changes are not converted", o.Diagnostics()[0].String())
+
+	diff := DiffSchema(parseMysqlSchema(tableA+tableB, &Diagnostics{}), parseMysqlSchema(tableA+checkB, &Diagnostics{}))
+	assert.Empty(t, diff.DropTables)
+	assert.Empty(t, diff.CreateTables)
+}
+
+func TestDMDBDiff_UnmappedColumnType(t *testing.T) {
+	oldSQL := "CREATE TABLE `event` (`id` bigint NOT NULL, `season` year DEFAULT NULL, `duration` int DEFAULT NULL) ENGINE=InnoDB;"
+	newSQL := "CREATE TABLE `event` (`id` bigint NOT NULL, `season` year DEFAULT NULL COMMENT '季', `duration` time DEFAULT NULL, " +
+		"`created_at` timestamp NULL DEFAULT NULL COMMENT '创建时间') ENGINE=InnoDB;"
+
+	o := NewDMDBDiff(oldSQL, newSQL, DMDBOptions{})
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Contains(t, messages, "[WARN] event.created_at: column type 'timestamp' has no DM mapping, column skipped, add it to the type mapping to convert it")
+	assert.Contains(t, messages, "[WARN] event.duration: column type 'time' has no DM mapping, column skipped, add it to the type mapping to convert it")
+	assert.NotContains(t, messages, "[WARN] event.season: column type 'year' has no DM mapping, column skipped, add it to the type mapping to convert it")
+}
+
+func TestDMDBDiff_TableCharset(t *testing.T) {
+	oldSQL := "CREATE TABLE `reply` (`id` int NOT NULL, `title` varchar(64) NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=latin1;"
+	newSQL := "CREATE TABLE `reply` (`id` int NOT NULL, `title` varchar(64) NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	diff := DiffSchema(parseMysqlSchema(oldSQL, &Diagnostics{}), parseMysqlSchema(newSQL, &Diagnostics{}))
+	assert.Len(t, diff.AlterTables, 1)
+	assert.Len(t, diff.AlterTables[0].ModifyColumns, 1)
+	assert.Equal(t, "title", diff.AlterTables[0].ModifyColumns[0].New.Name)
+
+	// 按字节输出长度时字符集影响列的长度，按字符输出时转换结果相同
+	got, err := NewDMDBDiff(oldSQL, newSQL, DMDBOptions{CharLength: CharLengthByte}).Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, "ALTER TABLE reply MODIFY title varchar2(256) NOT NULL;", got)
+
+	got, err = NewDMDBDiff(oldSQL, newSQL, DMDBOptions{}).Exec()
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,82 @@
+package convertor
+
+// ExecDown 输出撤销 Exec 结果的回滚语句：先删除 Exec 添加的外键，再按相反的顺序撤销每条语句。
+// 被删除的表、外键及分区的数据无法恢复，只给出提示
+func (o *DMDB) ExecDown() (string, error) {
+	var container = NewContainerWithSuffix("\n/\n", true)
+	var downs []Element
+	var foreignKeys []*ForeignKey
+
+	// 解析时的提示已在 Exec 中给出
+	schema := o.parseSchema(&Diagnostics{})
+	for _, stmt := range schema.Statements {
+		switch stmt.Kind {
+		case StatementDropTable:
+			o.diagnostics.Warnf(stmt.Drop.Name, "", "definition of the dropped table is unknown, DROP TABLE is not reverted")
+		case StatementCreateTable:
+			downs = append(downs, &dmdbDropTable{name: stmt.Table.Name})
+			foreignKeys = append(foreignKeys, stmt.Table.ForeignKeys...)
+		case StatementAlterTable:
+			alter := stmt.Alter
+			for _, name := range alter.DropForeignKeys {
+				var dropped bool
+				if foreignKeys, dropped = removeForeignKey(foreignKeys, alter.Name, name); !dropped {
+					o.diagnostics.Warnf(alter.Name, name, "definition of the dropped foreign key is unknown, DROP FOREIGN KEY is not reverted")
+				}
+			}
+			foreignKeys = append(foreignKeys, alter.AddForeignKeys...)
+			for _, partition := range alter.Partitions {
+				downs = append(downs, o.alterPartitionDown(alter.Name, partition)...)
+			}
+		}
+	}
+	for _, fk := range foreignKeys {
+		downs = append(downs, &dmdbDropForeignKey{tableName: fk.Table, name: fk.Name})
+	}
+
+	for i := len(downs) - 1; i >= 0; i-- {
+		container.Append(downs[i])
+	}
+	return container.Render(), nil
+}
+
+// alterPartitionDown 新增的分区回滚时删除，删除、清空的分区无法恢复
+func (o *DMDB) alterPartitionDown(tableName string, partition *AlterPartition) []Element {
+	if partition.Action != "ADD" {
+		o.diagnostics.Warnf(tableName, "", "data of %s PARTITION cannot be restored, not reverted", partition.Action)
+		return nil
+	}
+	var downs []Element
+	for _, def := range partition.Definitions {
+		if def != nil {
+			downs = append(downs, &dmdbAlterPartition{
+				conv:           o,
+				tableName:      tableName,
+				AlterPartition: &AlterPartition{Action: "DROP", Names: []string{def.Name}},
+			})
+		}
+	}
+	return downs
+}
+
+// ExecDown 输出撤销 Exec 结果的回滚语句，即从新结构迁移回旧结构的语句，列、表的注释恢复为旧 DDL 中的注释。
+// Exec 删除的表、列回滚时按旧定义重建，但其中的数据无法恢复
+func (o *DMDBDiff) ExecDown() (string, error) {
+	// 新旧 DDL 转换时的提示已在 Exec 中给出
+	oldSchema := parseMysqlSchema(o.oldSQL, &Diagnostics{})
+	newSchema := parseMysqlSchema(o.sql, &Diagnostics{})
+	diff := DiffSchema(oldSchema, newSchema)
+	for _, table := range diff.DropTables {
+		o.diagnostics.Warnf(table.Name, "", "dropped table is recreated empty on rollback")
+	}
+	for _, tableDiff := range diff.AlterTables {
+		for _, column := range tableDiff.DropColumns {
+			o.diagnostics.Warnf(tableDiff.Old.Name, column.Name, "dropped column is re-added without its data on rollback")
+		}
+	}
+
+	down := NewDMDBDiff(o.sql, o.oldSQL, o.options)
+	result, err := down.Exec()
+	o.diagnostics.list = append(o.diagnostics.list, down.Diagnostics()...)
+	return result, err
+}
@@ -0,0 +1,84 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDMDB_ExecDown(t *testing.T) {
+	sql := "DROP TABLE IF EXISTS `reply`;\n" +
+		"CREATE TABLE `draft` (`uuid` varchar(8) NOT NULL, PRIMARY KEY (`uuid`));\n" +
+		"CREATE TABLE `reply` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`draft_uuid` varchar(8) NOT NULL,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"KEY `idx_draft` (`draft_uuid`),\n" +
+		"CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`)\n" +
+		") PARTITION BY RANGE (id) (PARTITION p0 VALUES LESS THAN (1000));\n" +
+		"ALTER TABLE `reply` ADD PARTITION (PARTITION p1 VALUES LESS THAN MAXVALUE);\n" +
+		"ALTER TABLE `reply` DROP FOREIGN KEY `reply_legacy`;"
+
+	o := NewDMDBFromSQL(sql)
+	_, err := o.Exec()
+	assert.Nil(t, err)
+	got, err := o.ExecDown()
+	assert.Nil(t, err)
+	assert.Equal(t, "ALTER TABLE reply DROP CONSTRAINT fk_reply_reply_draft;\n/\n"+
+		"ALTER TABLE reply DROP PARTITION p1;\n/\n"+
+		"DROP TABLE reply;\n/\n"+
+		"DROP TABLE draft;", got)
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Contains(t, messages, "[WARN] reply: definition of the dropped table is unknown, DROP TABLE is not reverted")
+	assert.Contains(t, messages, "[WARN] reply.reply_legacy: definition of the dropped foreign key is unknown, DROP FOREIGN KEY is not reverted")
+}
+
+func TestDMDBDiff_ExecDown(t *testing.T) {
+	oldSQL := "CREATE TABLE `reply` (`id` bigint NOT NULL, `title` varchar(64) NOT NULL COMMENT '标题', `legacy` int, " +
+		"PRIMARY KEY (`id`)) COMMENT='回复';"
+	newSQL := "CREATE TABLE `reply` (`id` bigint NOT NULL, `title` varchar(128) COMMENT '回复标题', " +
+		"PRIMARY KEY (`id`), KEY `idx_title` (`title`)) COMMENT='回复表';"
+
+	o := NewDMDBDiff(oldSQL, newSQL, DMDBOptions{})
+	_, err := o.Exec()
+	assert.Nil(t, err)
+	got, err := o.ExecDown()
+	assert.Nil(t, err)
+	assert.Equal(t, "DROP INDEX idx_reply_idx_title;\n/\n"+
+		"ALTER TABLE reply ADD legacy int;\n/\n"+
+		"ALTER TABLE reply MODIFY title varchar2(64 CHAR) NOT NULL;\n/\n"+
+		"COMMENT ON COLUMN reply.title IS '标题';\n/\n"+
+		"COMMENT ON TABLE reply IS '回复';", got)
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Contains(t, messages, "[WARN] reply.legacy: dropped column is re-added without its data on rollback")
+}
+
+func TestDMDBDiff_ExecDownDiagnostics(t *testing.T) {
+	oldSQL := "CREATE TABLE `reply` (`id` bigint NOT NULL, `title` varchar(64) NOT NULL DEFAULT '', PRIMARY KEY (`id`));"
+	newSQL := "CREATE TABLE `reply` (`id` bigint NOT NULL, PRIMARY KEY (`id`));"
+
+	o := NewDMDBDiff(oldSQL, newSQL, DMDBOptions{})
+	_, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Empty(t, o.Diagnostics())
+	got, err := o.ExecDown()
+	assert.Nil(t, err)
+	assert.Equal(t, "ALTER TABLE reply ADD title varchar2(64 CHAR) NOT NULL;", got)
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Equal(t, []string{
+		"[WARN] reply.title: dropped column is re-added without its data on rollback",
+		"[WARN] reply.title: default value '' is not converted, adding the NOT NULL column fails if the table has rows",
+	}, messages)
+}
@@ -0,0 +1,100 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// mysqlWithDMFunctionMapping mysql 函数与达梦函数的对应关系，未列出的函数保持原样
+var mysqlWithDMFunctionMapping = map[string]string{
+	"ifnull":       "nvl",
+	"lcase":        "lower",
+	"ucase":        "upper",
+	"mid":          "substr",
+	"json_extract": "json_query",
+}
+
+// dmdbExprFormatter 将 mysql 表达式输出为达梦表达式
+type dmdbExprFormatter struct {
+	columnPrefix string // 列名前缀，例如触发器中的 :NEW.
+}
+
+// convertMysqlExpr 使用函数映射将 mysql 表达式转换为达梦表达式
+func convertMysqlExpr(expr string, columnPrefix string) (string, error) {
+	st, err := sqlparser.Parse("select " + expr)
+	if err != nil {
+		return "", err
+	}
+	sel, ok := st.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return "", fmt.Errorf("invalid expression '%s'", expr)
+	}
+	aliased, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return "", fmt.Errorf("invalid expression '%s'", expr)
+	}
+
+	f := &dmdbExprFormatter{columnPrefix: columnPrefix}
+	buf := sqlparser.NewTrackedBuffer(f.format)
+	buf.Myprintf("%v", aliased.Expr)
+	return buf.String(), nil
+}
+
+func (f *dmdbExprFormatter) format(buf *sqlparser.TrackedBuffer, node sqlparser.SQLNode) {
+	switch n := node.(type) {
+	case *sqlparser.ColName:
+		buf.WriteString(f.columnPrefix)
+		buf.WriteString(buildColumnName(n.Name.String()))
+	case *sqlparser.SQLVal:
+		if n.Type == sqlparser.StrVal {
+			buf.WriteString("'")
+			buf.WriteString(strings.ReplaceAll(string(n.Val), "'", "''"))
+			buf.WriteString("'")
+		} else {
+			n.Format(buf)
+		}
+	case *sqlparser.BinaryExpr:
+		switch n.Operator {
+		case sqlparser.JSONExtractOp:
+			buf.Myprintf("json_query(%v, %v)", n.Left, n.Right)
+		case sqlparser.JSONUnquoteExtractOp:
+			buf.Myprintf("json_value(%v, %v)", n.Left, n.Right)
+		default:
+			n.Format(buf)
+		}
+	case *sqlparser.FuncExpr:
+		f.formatFuncExpr(buf, n)
+	default:
+		node.Format(buf)
+	}
+}
+
+func (f *dmdbExprFormatter) formatFuncExpr(buf *sqlparser.TrackedBuffer, n *sqlparser.FuncExpr) {
+	name := n.Name.Lowered()
+	switch {
+	case name == "if" && len(n.Exprs) == 3:
+		// 达梦没有 IF 函数
+		buf.Myprintf("case when %v then %v else %v end", n.Exprs[0], n.Exprs[1], n.Exprs[2])
+		return
+	case name == "json_unquote" && len(n.Exprs) == 1:
+		// JSON_UNQUOTE(JSON_EXTRACT(doc, path)) 对应达梦的 JSON_VALUE(doc, path)
+		if aliased, ok := n.Exprs[0].(*sqlparser.AliasedExpr); ok {
+			if inner, ok := aliased.Expr.(*sqlparser.FuncExpr); ok && inner.Name.Lowered() == "json_extract" {
+				buf.Myprintf("json_value(%v)", inner.Exprs)
+				return
+			}
+		}
+	}
+	if mapped, found := mysqlWithDMFunctionMapping[name]; found {
+		name = mapped
+	} else {
+		name = n.Name.String()
+	}
+	var distinct string
+	if n.Distinct {
+		distinct = "distinct "
+	}
+	buf.Myprintf("%s(%s%v)", name, distinct, n.Exprs)
+}
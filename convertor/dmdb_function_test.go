@@ -0,0 +1,33 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_convertMysqlExpr(t *testing.T) {
+	tests := []struct {
+		expr   string
+		prefix string
+		want   string
+	}{
+		{expr: "concat(`first_name`, ' ', `last_name`)", want: "concat(first_name, ' ', last_name)"},
+		{expr: "if(`status` = 1, 'it''s on', 'off')", want: "case when status = 1 then 'it''s on' else 'off' end"},
+		{expr: "ifnull(`price`, 0) * `qty`", prefix: ":NEW.", want: "nvl(:NEW.price, 0) * :NEW.qty"},
+		{expr: "json_unquote(json_extract(`data`, '$.name'))", want: `json_value("data", '$.name')`},
+		{expr: "`data`->>'$.name'", want: `json_value("data", '$.name')`},
+		{expr: "`data`->'$.tags'", want: `json_query("data", '$.tags')`},
+		{expr: "substring(`name`, 1, 3)", want: "substr(name, 1, 3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := convertMysqlExpr(tt.expr, tt.prefix)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := convertMysqlExpr("price *", "")
+	assert.NotNil(t, err)
+}
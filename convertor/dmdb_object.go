@@ -0,0 +1,47 @@
+package convertor
+
+// DMDBObjectKind 转换结果中对象的类型
+type DMDBObjectKind string
+
+const (
+	DMDBObjectTable          DMDBObjectKind = "table"       // CREATE TABLE
+	DMDBObjectDropTable      DMDBObjectKind = "drop_table"  // DROP TABLE
+	DMDBObjectPrimaryKey     DMDBObjectKind = "primary_key" // 建表时添加的主键
+	DMDBObjectIndex          DMDBObjectKind = "index"
+	DMDBObjectComment        DMDBObjectKind = "comment" // 表注释的 Name 为空，列注释的 Name 为列名
+	DMDBObjectTrigger        DMDBObjectKind = "trigger" // 维护 STORED 生成列的触发器，Name 为列名
+	DMDBObjectForeignKey     DMDBObjectKind = "foreign_key"
+	DMDBObjectDropForeignKey DMDBObjectKind = "drop_foreign_key"
+	DMDBObjectPartition      DMDBObjectKind = "partition" // ALTER TABLE 中的分区操作
+)
+
+// DMDBObject 转换结果中的一个对象
+type DMDBObject struct {
+	Kind  DMDBObjectKind
+	Table string
+	Name  string // 索引、外键或列的名字
+	SQL   string
+}
+
+// Objects 按执行顺序返回转换结果中的对象，即按对象拆分的 Exec 输出
+func (o *DMDB) Objects() ([]*DMDBObject, error) {
+	o.resetDiagnostics()
+	var objects []*DMDBObject
+	for _, element := range o.elements() {
+		switch e := element.(type) {
+		case *dmdbCreateTable:
+			objects = append(objects, e.objects()...)
+		case *dmdbDropTableIfExists:
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectDropTable, Table: e.Name, SQL: e.Format()})
+		case *dmdbDropForeignKey:
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectDropForeignKey, Table: e.tableName, Name: e.name, SQL: e.Format()})
+		case *dmdbForeignKey:
+			objects = append(objects, &DMDBObject{Kind: DMDBObjectForeignKey, Table: e.Table, Name: e.Name, SQL: e.Format()})
+		case *dmdbAlterPartition:
+			if sql := e.Format(); len(sql) > 0 {
+				objects = append(objects, &DMDBObject{Kind: DMDBObjectPartition, Table: e.tableName, SQL: sql})
+			}
+		}
+	}
+	return objects, nil
+}
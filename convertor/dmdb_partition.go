@@ -0,0 +1,140 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+)
+
+var _ Element = (*dmdbPartitionDefinition)(nil)
+var _ Element = (*dmdbAlterPartition)(nil)
+
+// formatPartition 输出建表语句中的 PARTITION BY 子句，无法转换时返回空并给出提示，
+// 此时表按普通表创建
+func (o *dmdbCreateTable) formatPartition() string {
+	spec := o.Table.Partition
+	if spec == nil {
+		return ""
+	}
+	tableName := o.Table.Name
+
+	if spec.Subpartition {
+		o.conv.diagnostics.Warnf(tableName, "", "subpartitions are not supported, table created without partitioning")
+		return ""
+	}
+
+	// 达梦只支持按列分区
+	var columns []string
+	if len(spec.Expr) > 0 {
+		if !identListRegexp.MatchString(spec.Expr) {
+			o.conv.diagnostics.Warnf(tableName, "",
+				"partition expression '%s' is not supported by DM, table created without partitioning", spec.Expr)
+			return ""
+		}
+		columns = parseIdentList(spec.Expr)
+	}
+
+	method := spec.Method
+	if method == "KEY" {
+		// KEY 分区按 HASH 分区处理，未指定列时使用主键
+		method = "HASH"
+		if len(columns) == 0 {
+			columns = o.primaryKeyColumns()
+		}
+	}
+	if len(columns) == 0 {
+		o.conv.diagnostics.Warnf(tableName, "", "partition columns not found, table created without partitioning")
+		return ""
+	}
+	if spec.Linear {
+		o.conv.diagnostics.Infof(tableName, "", "LINEAR %s partitioning converted to %s partitioning", spec.Method, method)
+	}
+
+	switch method {
+	case "RANGE", "LIST":
+		if len(spec.Definitions) == 0 {
+			o.conv.diagnostics.Warnf(tableName, "", "%s partitioning without partition definitions, table created without partitioning", method)
+			return ""
+		}
+		if method == "LIST" && len(columns) > 1 {
+			o.conv.diagnostics.Warnf(tableName, "", "DM LIST partitioning supports only one column, table created without partitioning")
+			return ""
+		}
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "\nPARTITION BY %s(%s)", method, joinColumnNames(columns))
+	if len(spec.Definitions) > 0 {
+		definitions := NewContainerWithSuffix(",\n", true)
+		for _, def := range spec.Definitions {
+			definitions.Append(&dmdbPartitionDefinition{PartitionDefinition: def})
+		}
+		sb.WriteString(" (\n")
+		sb.WriteString(definitions.Render())
+		sb.WriteString(")")
+	} else if spec.Partitions > 0 {
+		_, _ = fmt.Fprintf(&sb, " PARTITIONS %d", spec.Partitions)
+	}
+	return sb.String()
+}
+
+func (o *dmdbCreateTable) primaryKeyColumns() []string {
+	if pk := o.Table.PrimaryKey(); pk != nil {
+		return pk.ColumnNames()
+	}
+	return nil
+}
+
+type dmdbPartitionDefinition struct {
+	*PartitionDefinition
+}
+
+func (d *dmdbPartitionDefinition) Format() string {
+	var sb strings.Builder
+	sb.WriteString("PARTITION ")
+	sb.WriteString(buildColumnName(d.Name))
+	switch {
+	case d.LessThan && d.MaxValue:
+		sb.WriteString(" VALUES LESS THAN (MAXVALUE)")
+	case d.LessThan:
+		_, _ = fmt.Fprintf(&sb, " VALUES LESS THAN (%s)", d.Values)
+	case len(d.Values) > 0:
+		_, _ = fmt.Fprintf(&sb, " VALUES (%s)", d.Values)
+	}
+	return sb.String()
+}
+
+// dmdbAlterPartition ALTER TABLE 中的分区操作，每个分区输出一条语句
+type dmdbAlterPartition struct {
+	conv      *DMDB
+	tableName string
+	*AlterPartition
+}
+
+func (d *dmdbAlterPartition) Format() string {
+	var statements []string
+	tableName := buildTableName(d.tableName)
+
+	switch d.Action {
+	case "ADD":
+		if d.Partitions > 0 {
+			d.conv.diagnostics.Warnf(d.tableName, "", "ADD PARTITION PARTITIONS %d is not supported by DM, skipped", d.Partitions)
+		}
+		for _, def := range d.Definitions {
+			if def == nil {
+				d.conv.diagnostics.Warnf(d.tableName, "", "subpartitions are not supported, ADD PARTITION skipped")
+				continue
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s;",
+				tableName, (&dmdbPartitionDefinition{PartitionDefinition: def}).Format()))
+		}
+	case "DROP", "TRUNCATE":
+		if d.All {
+			statements = append(statements, fmt.Sprintf("TRUNCATE TABLE %s;", tableName))
+		}
+		for _, name := range d.Names {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s %s PARTITION %s;",
+				tableName, d.Action, buildColumnName(name)))
+		}
+	}
+	return strings.Join(statements, "\n")
+}
@@ -0,0 +1,831 @@
+package convertor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 达梦的标识符，可以带模式名，例如 "user"、sysdba.t1
+const dmdbIdentPattern = `(?:"[^"]+"|[\w$#]+)(?:\s*\.\s*(?:"[^"]+"|[\w$#]+))?`
+
+var (
+	dmdbBlockRegexp       = regexp.MustCompile(`(?is)^(BEGIN|DECLARE|CREATE\s+(OR\s+REPLACE\s+)?(TRIGGER|PROCEDURE|FUNCTION))\b`)
+	dmdbCreateTableRegexp = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(` + dmdbIdentPattern + `)\s*\(`)
+	dmdbDropTableRegexp   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(IF\s+EXISTS\s+)?(` + dmdbIdentPattern + `)`)
+	dmdbDropBlockRegexp   = regexp.MustCompile(`(?is)EXECUTE\s+IMMEDIATE\s+'DROP\s+TABLE\s+(` + dmdbIdentPattern + `)'`)
+	dmdbTriggerRegexp     = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?TRIGGER\s+(` + dmdbIdentPattern + `)\s+.*?\bON\s+(` + dmdbIdentPattern + `)\s+FOR\s+EACH\s+ROW\s+BEGIN\s+:NEW\.(` + dmdbIdentPattern + `)\s*:=\s*(.*?);\s*END\s*;?$`)
+	dmdbCommentRegexp     = regexp.MustCompile(`(?is)^COMMENT\s+ON\s+(TABLE|COLUMN)\s+(` + dmdbIdentPattern + `)\s+IS\s+('.*')$`)
+	dmdbCreateIndexRegexp = regexp.MustCompile(`(?is)^CREATE\s+(?:(UNIQUE|CONTEXT|SPATIAL|BITMAP)\s+)?INDEX\s+(` + dmdbIdentPattern + `)\s+ON\s+(` + dmdbIdentPattern + `)\s*\(`)
+	dmdbAlterTableRegexp  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(` + dmdbIdentPattern + `)\s+(.*)$`)
+	dmdbAddPKRegexp       = regexp.MustCompile(`(?is)^ADD\s+(?:CONSTRAINT\s+` + dmdbIdentPattern + `\s+)?PRIMARY\s+KEY\s*\((.*)\)$`)
+	dmdbAddFKRegexp       = regexp.MustCompile(`(?is)^ADD\s+(?:CONSTRAINT\s+(` + dmdbIdentPattern + `)\s+)?(FOREIGN\s+KEY.*)$`)
+	dmdbDropConstraint    = regexp.MustCompile(`(?is)^DROP\s+CONSTRAINT\s+(` + dmdbIdentPattern + `)$`)
+
+	dmdbConstraintRegexp = regexp.MustCompile(`(?is)^(?:CONSTRAINT\s+(` + dmdbIdentPattern + `)\s+)?(PRIMARY\s+KEY|UNIQUE|FOREIGN\s+KEY|CHECK)\b\s*(.*)$`)
+	dmdbForeignKeyRegexp = regexp.MustCompile(`(?is)^FOREIGN\s+KEY\s*\(([^)]*)\)\s*REFERENCES\s+(` + dmdbIdentPattern + `)\s*\(([^)]*)\)\s*(.*)$`)
+	dmdbOnDeleteRegexp   = regexp.MustCompile(`(?is)ON\s+DELETE\s+(CASCADE|SET\s+NULL|SET\s+DEFAULT|NO\s+ACTION|RESTRICT)`)
+	dmdbPartitionRegexp  = regexp.MustCompile(`(?is)^\s*PARTITION\s+BY\s+(RANGE|LIST|HASH)\s*\(([^)]*)\)\s*(.*)$`)
+	dmdbPartitionsRegexp = regexp.MustCompile(`(?is)^PARTITIONS\s+(\d+)`)
+	dmdbPartitionDefRe   = regexp.MustCompile(`(?is)^PARTITION\s+(` + dmdbIdentPattern + `)\s+VALUES\s+(LESS\s+THAN\s+)?\((.*)\)$`)
+
+	dmdbColumnRegexp     = regexp.MustCompile(`(?s)^(` + dmdbIdentPattern + `)\s+(.*)$`)
+	dmdbColumnTypeRegexp = regexp.MustCompile(`(?is)^(` + dmdbIdentPattern + `(?:\s+PRECISION)?)\s*(?:\(\s*(\d+)\s*(CHAR|BYTE)?\s*(?:,\s*(\d+)\s*)?\))?`)
+	dmdbIdentityRegexp   = regexp.MustCompile(`(?is)^(?:AUTO_INCREMENT|IDENTITY(?:\s*\(\s*\d+\s*,\s*\d+\s*\))?)`)
+	dmdbGeneratedRegexp  = regexp.MustCompile(`(?is)^(?:GENERATED\s+ALWAYS\s+)?AS\s*\(`)
+	dmdbDefaultRegexp    = regexp.MustCompile(`(?is)^DEFAULT\s+`)
+	dmdbDefaultValRegexp = regexp.MustCompile(`(?is)^-?[\w.:$]+(?:\s*\(\s*\))?`)
+	dmdbCheckRegexp      = regexp.MustCompile(`(?is)^(?:CONSTRAINT\s+` + dmdbIdentPattern + `\s+)?CHECK\s*\(`)
+	dmdbNotNullRegexp    = regexp.MustCompile(`(?is)^NOT\s+NULL\b`)
+	dmdbNullRegexp       = regexp.MustCompile(`(?is)^NULL\b`)
+	dmdbVirtualRegexp    = regexp.MustCompile(`(?is)^VIRTUAL\b`)
+
+	dmdbIsJSONRegexp   = regexp.MustCompile(`(?is)^(` + dmdbIdentPattern + `)\s+IS\s+JSON$`)
+	dmdbUnsignedRegexp = regexp.MustCompile(`(?is)^(` + dmdbIdentPattern + `)\s*>=\s*0$`)
+	dmdbSRIDRegexp     = regexp.MustCompile(`(?is)^DMGEO\.ST_SRID\((` + dmdbIdentPattern + `)\)\s*=\s*(\d+)$`)
+	dmdbInListRegexp   = regexp.MustCompile(`(?is)^(` + dmdbIdentPattern + `)\s+IN\s*\((.*)\)$`)
+	dmdbSetRegexp      = regexp.MustCompile(`(?is)^REGEXP_LIKE\((` + dmdbIdentPattern + `)\s*,\s*'\^\(\((.*)\)\(,\(.*\)\)\*\)\?\$'\)$`)
+
+	dmdbUpperRegexp  = regexp.MustCompile(`(?is)^UPPER\s*\((.*)\)$`)
+	dmdbSubstrRegexp = regexp.MustCompile(`(?is)^SUBSTR\s*\((` + dmdbIdentPattern + `)\s*,\s*1\s*,\s*(\d+)\s*\)$`)
+	dmdbIndexColRe   = regexp.MustCompile(`(?is)^(` + dmdbIdentPattern + `)(?:\s+(?:ASC|DESC))?$`)
+)
+
+// 达梦类型到 mysql 类型的映射，长度、精度的处理见 dmdbSchemaParser.columnType
+var dmdbWithMysqlDatatypeMapping = map[string]string{
+	"char":             "char",
+	"character":        "char",
+	"nchar":            "char",
+	"varchar":          "varchar",
+	"varchar2":         "varchar",
+	"nvarchar":         "varchar",
+	"nvarchar2":        "varchar",
+	"text":             "longtext",
+	"long":             "longtext",
+	"longvarchar":      "longtext",
+	"clob":             "longtext",
+	"blob":             "longblob",
+	"image":            "longblob",
+	"longvarbinary":    "longblob",
+	"binary":           "binary",
+	"varbinary":        "varbinary",
+	"bit":              "tinyint",
+	"bool":             "tinyint",
+	"boolean":          "tinyint",
+	"byte":             "tinyint",
+	"tinyint":          "tinyint",
+	"smallint":         "smallint",
+	"int":              "int",
+	"integer":          "int",
+	"pls_integer":      "int",
+	"bigint":           "bigint",
+	"numeric":          "decimal",
+	"decimal":          "decimal",
+	"dec":              "decimal",
+	"number":           "decimal",
+	"real":             "float",
+	"float":            "double",
+	"double":           "double",
+	"double precision": "double",
+	"date":             "date",
+	"time":             "time",
+	"datetime":         "datetime",
+	"timestamp":        "datetime",
+}
+
+// ParseDMDBSchema 解析 myto 输出的达梦 DDL（包括在达梦中手工修改后的 DDL），返回与目标数据库无关的结构，
+// buildPKName、buildIdxName 等添加的 pk_、unq_、idx_、fk_ 前缀会被去掉
+func ParseDMDBSchema(sql string) (*Schema, []Diagnostic) {
+	var diagnostics Diagnostics
+	schema := parseDMDBSchema(sql, &diagnostics)
+	return schema, diagnostics.List()
+}
+
+func parseDMDBSchema(sql string, diagnostics *Diagnostics) *Schema {
+	p := &dmdbSchemaParser{schema: &Schema{}, tables: map[string]*Table{}, diagnostics: diagnostics}
+	for _, stmt := range splitDMDBStatements(sql) {
+		p.parseStatement(stmt)
+	}
+	return p.schema
+}
+
+type dmdbSchemaParser struct {
+	schema      *Schema
+	tables      map[string]*Table // key 为小写的表名
+	diagnostics *Diagnostics
+}
+
+func (p *dmdbSchemaParser) parseStatement(stmt string) {
+	switch {
+	case dmdbCreateTableRegexp.MatchString(stmt):
+		p.parseCreateTable(stmt)
+	case dmdbDropTableRegexp.MatchString(stmt):
+		matches := dmdbDropTableRegexp.FindStringSubmatch(stmt)
+		p.appendDropTable(trimDMDBIdent(matches[2]), len(matches[1]) > 0)
+	case dmdbDropBlockRegexp.MatchString(stmt) && strings.HasPrefix(strings.ToUpper(stmt), "BEGIN"):
+		// myto 输出的 DROP TABLE IF EXISTS
+		p.appendDropTable(trimDMDBIdent(dmdbDropBlockRegexp.FindStringSubmatch(stmt)[1]), true)
+	case dmdbTriggerRegexp.MatchString(stmt):
+		p.parseTrigger(stmt)
+	case dmdbCommentRegexp.MatchString(stmt):
+		p.parseComment(stmt)
+	case dmdbCreateIndexRegexp.MatchString(stmt):
+		p.parseCreateIndex(stmt)
+	case dmdbAlterTableRegexp.MatchString(stmt):
+		matches := dmdbAlterTableRegexp.FindStringSubmatch(stmt)
+		p.parseAlterTable(trimDMDBIdent(matches[1]), strings.TrimSpace(matches[2]))
+	default:
+		p.diagnostics.Warnf("", "", "unsupported statement skipped: %s", firstLine(stmt))
+	}
+}
+
+func (p *dmdbSchemaParser) appendDropTable(name string, ifExists bool) {
+	p.schema.Statements = append(p.schema.Statements, &Statement{
+		Kind: StatementDropTable,
+		Drop: &DropTable{Name: name, IfExists: ifExists},
+	})
+}
+
+// table 返回本次解析中已创建的表，不存在时给出提示并返回 nil
+func (p *dmdbSchemaParser) table(name, object, what string) *Table {
+	if table, found := p.tables[strings.ToLower(name)]; found {
+		return table
+	}
+	p.diagnostics.Warnf(name, object, "%s skipped, table is not created in the same script", what)
+	return nil
+}
+
+func (p *dmdbSchemaParser) parseCreateTable(stmt string) {
+	start := indexSQL(stmt, '(')
+	end := matchParen(stmt, start)
+	name := trimDMDBIdent(dmdbCreateTableRegexp.FindStringSubmatch(stmt)[1])
+	if end < 0 {
+		p.diagnostics.Warnf(name, "", "invalid CREATE TABLE statement skipped")
+		return
+	}
+
+	table := &Table{Name: name}
+	var checks []string
+	for _, item := range splitSQL(stmt[start+1:end], ',') {
+		item = strings.TrimSpace(item)
+		if matches := dmdbConstraintRegexp.FindStringSubmatch(item); matches != nil {
+			checks = append(checks, p.parseTableConstraint(table, trimDMDBIdent(matches[1]), strings.ToUpper(matches[2]), matches[3])...)
+			continue
+		}
+		if column := p.parseColumn(table, item); column != nil {
+			table.Columns = append(table.Columns, column)
+		}
+	}
+	for _, check := range checks {
+		p.applyCheck(table, nil, check)
+	}
+	table.Partition = p.parsePartition(table, stmt[end+1:])
+
+	p.tables[strings.ToLower(name)] = table
+	p.schema.Statements = append(p.schema.Statements, &Statement{Kind: StatementCreateTable, Table: table})
+}
+
+// parseTableConstraint 解析建表语句中的表级约束，返回 CHECK 约束的表达式
+func (p *dmdbSchemaParser) parseTableConstraint(table *Table, name, kind, body string) []string {
+	body = strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(kind, "PRIMARY"):
+		table.Indexes = append([]*Index{{Name: "PRIMARY", Kind: IndexPrimary, Columns: parseDMDBIndexColumns(trimParens(body))}}, table.Indexes...)
+	case kind == "UNIQUE":
+		table.Indexes = append(table.Indexes, &Index{
+			Name:    trimDMDBName("unq_", table.Name, name),
+			Kind:    IndexUnique,
+			Columns: parseDMDBIndexColumns(trimParens(body)),
+		})
+	case strings.HasPrefix(kind, "FOREIGN"):
+		if fk := p.parseForeignKey(table.Name, name, "FOREIGN KEY "+body); fk != nil {
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	case kind == "CHECK":
+		return []string{trimParens(body)}
+	}
+	return nil
+}
+
+// parseColumn 解析列定义，列约束中的 CHECK 用于还原 ENUM、SET、JSON 等类型
+func (p *dmdbSchemaParser) parseColumn(table *Table, item string) *Column {
+	fields := dmdbColumnRegexp.FindStringSubmatch(item)
+	if fields == nil {
+		p.diagnostics.Warnf(table.Name, "", "invalid column definition skipped: %s", item)
+		return nil
+	}
+	column := &Column{Name: trimDMDBIdent(fields[1])}
+	rest := fields[2]
+
+	typeMatches := dmdbColumnTypeRegexp.FindStringSubmatch(rest)
+	if typeMatches == nil {
+		p.diagnostics.Warnf(table.Name, column.Name, "invalid column type skipped: %s", rest)
+		return nil
+	}
+	column.Type = p.columnType(table.Name, column.Name, typeMatches[1], typeMatches[2], typeMatches[4])
+	rest = strings.TrimSpace(rest[len(typeMatches[0]):])
+
+	var checks []string
+	for len(rest) > 0 {
+		var n int
+		switch {
+		case dmdbIdentityRegexp.MatchString(rest):
+			column.AutoIncrement = true
+			n = len(dmdbIdentityRegexp.FindString(rest))
+		case dmdbGeneratedRegexp.MatchString(rest):
+			open := len(dmdbGeneratedRegexp.FindString(rest)) - 1
+			closing := matchParen(rest, open)
+			if closing < 0 {
+				rest = ""
+				continue
+			}
+			column.Generated = &GeneratedColumn{Expr: strings.TrimSpace(rest[open+1 : closing])}
+			rest = strings.TrimSpace(rest[closing+1:])
+			n = len(dmdbVirtualRegexp.FindString(rest))
+		case dmdbDefaultRegexp.MatchString(rest):
+			n = len(dmdbDefaultRegexp.FindString(rest))
+			value, size := parseDMDBDefault(rest[n:])
+			column.Default = value
+			n += size
+		case dmdbCheckRegexp.MatchString(rest):
+			open := len(dmdbCheckRegexp.FindString(rest)) - 1
+			closing := matchParen(rest, open)
+			if closing < 0 {
+				rest = ""
+				continue
+			}
+			checks = append(checks, strings.TrimSpace(rest[open+1:closing]))
+			n = closing + 1
+		case dmdbNotNullRegexp.MatchString(rest):
+			column.NotNull = true
+			n = len(dmdbNotNullRegexp.FindString(rest))
+		case dmdbNullRegexp.MatchString(rest):
+			n = len(dmdbNullRegexp.FindString(rest))
+		default:
+			p.diagnostics.Warnf(table.Name, column.Name, "column attribute '%s' is not supported, ignored", firstLine(rest))
+			n = len(rest)
+		}
+		rest = strings.TrimSpace(rest[n:])
+	}
+	for _, check := range checks {
+		p.applyCheck(table, column, check)
+	}
+	return column
+}
+
+// columnType 将达梦类型转换为 mysql 类型
+func (p *dmdbSchemaParser) columnType(tableName, columnName, typeName, length, scale string) ColumnType {
+	name := strings.ToLower(strings.Join(strings.Fields(trimDMDBIdent(typeName)), " "))
+	result := ColumnType{Length: atoiPtr(length), Scale: atoiPtr(scale)}
+
+	if names := splitDMDBIdent(typeName); len(names) == 2 && strings.EqualFold(names[0], "SYSGEO") {
+		for mysqlType, dmType := range mysqlWithDMDatatypeMapping {
+			if strings.EqualFold(dmType, "SYSGEO."+names[1]) {
+				return ColumnType{Name: mysqlType}
+			}
+		}
+	}
+	mysqlType, found := dmdbWithMysqlDatatypeMapping[name]
+	if !found {
+		p.diagnostics.Warnf(tableName, columnName, "DM type '%s' has no mysql mapping, kept as is", typeName)
+		result.Name = name
+		return result
+	}
+	result.Name = mysqlType
+
+	switch name {
+	case "bit", "bool", "boolean":
+		result.Length, result.Scale = intPtr(1), nil
+	case "number", "numeric", "decimal", "dec":
+		if result.Length == nil {
+			p.diagnostics.Infof(tableName, columnName, "%s without precision converted to double", name)
+			result.Name = "double"
+		}
+	case "varchar", "varchar2", "nvarchar", "nvarchar2":
+		if result.Length == nil {
+			p.diagnostics.Infof(tableName, columnName, "%s without length converted to longtext", name)
+			result.Name = "longtext"
+		}
+	case "varbinary":
+		if result.Length == nil {
+			result.Name = "longblob"
+		}
+	case "text", "long", "longvarchar", "clob", "blob", "image", "longvarbinary",
+		"tinyint", "byte", "smallint", "int", "integer", "pls_integer", "bigint",
+		"real", "float", "double", "double precision", "date":
+		// mysql 中这些类型的长度只表示显示宽度，或者没有长度
+		result.Length, result.Scale = nil, nil
+	}
+	return result
+}
+
+// applyCheck 按 myto 输出的 CHECK 约束还原列的类型，column 为空时按约束中的列名查找
+func (p *dmdbSchemaParser) applyCheck(table *Table, column *Column, check string) {
+	lookup := func(name string) *Column {
+		if column != nil && strings.EqualFold(column.Name, trimDMDBIdent(name)) {
+			return column
+		}
+		return table.Column(trimDMDBIdent(name))
+	}
+
+	if matches := dmdbIsJSONRegexp.FindStringSubmatch(check); matches != nil {
+		if col := lookup(matches[1]); col != nil {
+			col.Type = ColumnType{Name: "json"}
+			return
+		}
+	}
+	if matches := dmdbUnsignedRegexp.FindStringSubmatch(check); matches != nil {
+		if col := lookup(matches[1]); col != nil {
+			col.Type.Unsigned = true
+			return
+		}
+	}
+	if matches := dmdbSRIDRegexp.FindStringSubmatch(check); matches != nil {
+		if col := lookup(matches[1]); col != nil {
+			col.SRID, _ = strconv.Atoi(matches[2])
+			return
+		}
+	}
+	if matches := dmdbInListRegexp.FindStringSubmatch(check); matches != nil {
+		if col := lookup(matches[1]); col != nil && col.Type.Name == "varchar" {
+			col.Type = ColumnType{Name: "enum", Values: parseDMDBStringList(matches[2])}
+			return
+		}
+	}
+	if matches := dmdbSetRegexp.FindStringSubmatch(check); matches != nil {
+		if col := lookup(matches[1]); col != nil && col.Type.Name == "varchar" {
+			var values []string
+			for _, v := range splitRegexpAlternatives(strings.ReplaceAll(matches[2], "''", "'")) {
+				values = append(values, unquoteRegexp(v))
+			}
+			col.Type = ColumnType{Name: "set", Values: values}
+			return
+		}
+	}
+	var object string
+	if column != nil {
+		object = column.Name
+	}
+	p.diagnostics.Warnf(table.Name, object, "check constraint '%s' is not supported, skipped", check)
+}
+
+// parsePartition 解析建表语句末尾的 PARTITION BY 子句，RANGE、LIST 分区转换为 mysql 的 COLUMNS 分区
+func (p *dmdbSchemaParser) parsePartition(table *Table, tail string) *Partition {
+	matches := dmdbPartitionRegexp.FindStringSubmatch(tail)
+	if matches == nil {
+		return nil
+	}
+	partition := &Partition{Method: strings.ToUpper(matches[1]), Expr: strings.TrimSpace(matches[2])}
+	var columns []string
+	for _, col := range splitSQL(partition.Expr, ',') {
+		columns = append(columns, trimDMDBIdent(col))
+	}
+	partition.Expr = strings.Join(columns, ",")
+	partition.Columns = partition.Method != "HASH"
+
+	rest := strings.TrimSpace(matches[3])
+	if m := dmdbPartitionsRegexp.FindStringSubmatch(rest); m != nil {
+		partition.Partitions, _ = strconv.Atoi(m[1])
+		return partition
+	}
+	if !strings.HasPrefix(rest, "(") {
+		return partition
+	}
+	end := matchParen(rest, 0)
+	if end < 0 {
+		p.diagnostics.Warnf(table.Name, "", "invalid partition definitions skipped")
+		return nil
+	}
+	for _, def := range splitSQL(rest[1:end], ',') {
+		m := dmdbPartitionDefRe.FindStringSubmatch(strings.TrimSpace(def))
+		if m == nil {
+			p.diagnostics.Warnf(table.Name, "", "partition definition '%s' is not supported, skipped", strings.TrimSpace(def))
+			continue
+		}
+		definition := &PartitionDefinition{Name: trimDMDBIdent(m[1]), LessThan: len(m[2]) > 0, Values: strings.TrimSpace(m[3])}
+		if strings.EqualFold(definition.Values, "MAXVALUE") {
+			definition.Values, definition.MaxValue = "", true
+		}
+		partition.Definitions = append(partition.Definitions, definition)
+	}
+	return partition
+}
+
+// parseTrigger myto 使用触发器维护 STORED 生成列，这类触发器还原为生成列，其他触发器被忽略
+func (p *dmdbSchemaParser) parseTrigger(stmt string) {
+	matches := dmdbTriggerRegexp.FindStringSubmatch(stmt)
+	triggerName, tableName, columnName := trimDMDBIdent(matches[1]), trimDMDBIdent(matches[2]), trimDMDBIdent(matches[3])
+	table := p.table(tableName, triggerName, "trigger")
+	if table == nil {
+		return
+	}
+	column := table.Column(columnName)
+	if column == nil || !strings.EqualFold(triggerName, buildIdxName("trg_", tableName, columnName)) {
+		p.diagnostics.Warnf(tableName, triggerName, "trigger is not supported, skipped")
+		return
+	}
+	column.Generated = &GeneratedColumn{
+		Expr:   strings.ReplaceAll(strings.TrimSpace(matches[4]), ":NEW.", ""),
+		Stored: true,
+	}
+	p.diagnostics.Infof(tableName, columnName, "trigger %s converted to STORED generated column", triggerName)
+}
+
+func (p *dmdbSchemaParser) parseComment(stmt string) {
+	matches := dmdbCommentRegexp.FindStringSubmatch(stmt)
+	comment := unquoteDMDBString(matches[3])
+	if strings.EqualFold(matches[1], "TABLE") {
+		if table := p.table(trimDMDBIdent(matches[2]), "", "table comment"); table != nil {
+			table.Comment = comment
+		}
+		return
+	}
+
+	names := splitDMDBIdent(matches[2])
+	if len(names) < 2 {
+		p.diagnostics.Warnf("", names[0], "column comment skipped, table name not found")
+		return
+	}
+	tableName, columnName := names[len(names)-2], names[len(names)-1]
+	table := p.table(tableName, columnName, "column comment")
+	if table == nil {
+		return
+	}
+	if column := table.Column(columnName); column != nil {
+		column.Comment = comment
+	} else {
+		p.diagnostics.Warnf(tableName, columnName, "column comment skipped, column not found")
+	}
+}
+
+func (p *dmdbSchemaParser) parseCreateIndex(stmt string) {
+	matches := dmdbCreateIndexRegexp.FindStringSubmatch(stmt)
+	kind, name, tableName := strings.ToUpper(matches[1]), trimDMDBIdent(matches[2]), trimDMDBIdent(matches[3])
+	table := p.table(tableName, name, "index")
+	if table == nil {
+		return
+	}
+	open := len(matches[0]) - 1
+	end := matchParen(stmt, open)
+	if end < 0 {
+		p.diagnostics.Warnf(tableName, name, "invalid index skipped")
+		return
+	}
+
+	index := &Index{Kind: IndexNormal, Name: trimDMDBName("idx_", tableName, name)}
+	switch kind {
+	case "UNIQUE":
+		index.Kind, index.Name = IndexUnique, trimDMDBName("unq_", tableName, name)
+	case "CONTEXT":
+		index.Kind = IndexFulltext
+		if strings.Contains(strings.ToUpper(stmt[end:]), "CHINESE_VGRAM_LEXER") {
+			index.Parser = "ngram"
+		}
+	case "SPATIAL":
+		index.Kind = IndexSpatial
+	case "BITMAP":
+		p.diagnostics.Infof(tableName, name, "bitmap index converted to normal index")
+	}
+
+	columns, err := parseDMDBIndexExprs(stmt[open+1 : end])
+	if len(err) > 0 {
+		p.diagnostics.Warnf(tableName, name, "function index on '%s' is not supported, skipped", err)
+		return
+	}
+	index.Columns = columns
+	table.Indexes = append(table.Indexes, index)
+}
+
+func (p *dmdbSchemaParser) parseAlterTable(tableName, spec string) {
+	switch {
+	case dmdbAddPKRegexp.MatchString(spec):
+		table := p.table(tableName, "PRIMARY", "primary key")
+		if table == nil {
+			return
+		}
+		columns := parseDMDBIndexColumns(dmdbAddPKRegexp.FindStringSubmatch(spec)[1])
+		table.Indexes = append([]*Index{{Name: "PRIMARY", Kind: IndexPrimary, Columns: columns}}, table.Indexes...)
+	case dmdbAddFKRegexp.MatchString(spec):
+		matches := dmdbAddFKRegexp.FindStringSubmatch(spec)
+		if fk := p.parseForeignKey(tableName, trimDMDBIdent(matches[1]), matches[2]); fk != nil {
+			p.alterTable(tableName).AddForeignKeys = append(p.alterTable(tableName).AddForeignKeys, fk)
+		}
+	case dmdbDropConstraint.MatchString(spec):
+		name := trimDMDBIdent(dmdbDropConstraint.FindStringSubmatch(spec)[1])
+		alter := p.alterTable(tableName)
+		alter.DropForeignKeys = append(alter.DropForeignKeys, trimDMDBName("fk_", tableName, name))
+	default:
+		p.diagnostics.Warnf(tableName, "", "unsupported ALTER TABLE skipped: %s", firstLine(spec))
+	}
+}
+
+// alterTable 连续的 ALTER TABLE 合并为一条语句
+func (p *dmdbSchemaParser) alterTable(tableName string) *AlterTable {
+	if n := len(p.schema.Statements); n > 0 {
+		if last := p.schema.Statements[n-1]; last.Kind == StatementAlterTable && last.Alter.Name == tableName {
+			return last.Alter
+		}
+	}
+	alter := &AlterTable{Name: tableName}
+	p.schema.Statements = append(p.schema.Statements, &Statement{Kind: StatementAlterTable, Alter: alter})
+	return alter
+}
+
+func (p *dmdbSchemaParser) parseForeignKey(tableName, name, spec string) *ForeignKey {
+	matches := dmdbForeignKeyRegexp.FindStringSubmatch(strings.TrimSpace(spec))
+	if matches == nil {
+		p.diagnostics.Warnf(tableName, name, "invalid foreign key skipped")
+		return nil
+	}
+	fk := &ForeignKey{
+		Name:       trimDMDBName("fk_", tableName, name),
+		Table:      tableName,
+		Columns:    splitDMDBIdentList(matches[1]),
+		RefTable:   trimDMDBIdent(matches[2]),
+		RefColumns: splitDMDBIdentList(matches[3]),
+	}
+	if m := dmdbOnDeleteRegexp.FindStringSubmatch(matches[4]); m != nil {
+		fk.OnDelete = strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))
+	}
+	return fk
+}
+
+// splitDMDBStatements 按 ; 及单独一行的 / 拆分语句，BEGIN ... END 块及触发器等作为一条语句
+func splitDMDBStatements(sql string) []string {
+	var result []string
+	var start = -1
+	var block bool
+	var blockDepth int
+
+	flush := func(end int) {
+		if start >= 0 {
+			if stmt := strings.TrimSpace(sql[start:end]); len(stmt) > 0 {
+				result = append(result, stmt)
+			}
+		}
+		start, block, blockDepth = -1, false, 0
+	}
+
+	scanSQL(sql, func(i, depth int) bool {
+		c := sql[i]
+		if start < 0 {
+			if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';' || c == '/' {
+				return true
+			}
+			start = i
+			block = dmdbBlockRegexp.MatchString(sql[i:])
+		}
+		if block {
+			if isIdentStart(sql, i) {
+				word := strings.ToUpper(identAt(sql, i))
+				switch word {
+				case "BEGIN", "CASE":
+					blockDepth++
+				case "END":
+					next := strings.ToUpper(identAt(sql, skipSpaces(sql, i+3)))
+					if next != "IF" && next != "LOOP" && next != "WHILE" {
+						blockDepth--
+					}
+				}
+			}
+			if c == ';' && blockDepth <= 0 && depth == 0 {
+				flush(i + 1)
+			}
+			return true
+		}
+		if depth == 0 && (c == ';' || (c == '/' && isSlashLine(sql, i))) {
+			flush(i)
+		}
+		return true
+	})
+	flush(len(sql))
+	return result
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentStart(sql string, i int) bool {
+	return isIdentChar(sql[i]) && (i == 0 || !isIdentChar(sql[i-1]))
+}
+
+func identAt(sql string, i int) string {
+	j := i
+	for j < len(sql) && isIdentChar(sql[j]) {
+		j++
+	}
+	return sql[i:j]
+}
+
+func skipSpaces(sql string, i int) int {
+	for i < len(sql) && (sql[i] == ' ' || sql[i] == '\t' || sql[i] == '\r' || sql[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// isSlashLine 判断 sql[i] 处的 / 是否单独占一行
+func isSlashLine(sql string, i int) bool {
+	lineStart := strings.LastIndexByte(sql[:i], '\n') + 1
+	lineEnd := strings.IndexByte(sql[i:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(sql)
+	} else {
+		lineEnd += i
+	}
+	return strings.TrimSpace(sql[lineStart:lineEnd]) == "/"
+}
+
+// parseDMDBDefault 解析 DEFAULT 之后的值，返回默认值及其在 s 中的长度
+func parseDMDBDefault(s string) (*DefaultValue, int) {
+	if strings.HasPrefix(s, "'") {
+		end := skipQuoted(s, 0)
+		if end >= len(s) {
+			return &DefaultValue{Kind: DefaultString, Value: unquoteDMDBString(s)}, len(s)
+		}
+		return &DefaultValue{Kind: DefaultString, Value: unquoteDMDBString(s[:end+1])}, end + 1
+	}
+	if strings.HasPrefix(s, "(") {
+		if end := matchParen(s, 0); end > 0 {
+			value, _ := parseDMDBDefault(strings.TrimSpace(s[1:end]))
+			return value, end + 1
+		}
+	}
+	token := dmdbDefaultValRegexp.FindString(s)
+	upper := strings.ToUpper(token)
+	switch {
+	case upper == "NULL":
+		return &DefaultValue{Kind: DefaultNull}, len(token)
+	case strings.HasPrefix(upper, "0X"):
+		if bits, ok := hexToBits(token[2:]); ok {
+			return &DefaultValue{Kind: DefaultBits, Value: bits}, len(token)
+		}
+	case isNumber(token):
+		return &DefaultValue{Kind: DefaultNumber, Value: token}, len(token)
+	}
+	switch strings.TrimSuffix(strings.ReplaceAll(upper, " ", ""), "()") {
+	case "CURRENT_TIMESTAMP", "SYSDATE", "NOW", "SYSTIMESTAMP", "LOCALTIMESTAMP", "GETDATE":
+		return &DefaultValue{Kind: DefaultCurrentTimestamp, Value: "CURRENT_TIMESTAMP"}, len(token)
+	}
+	return &DefaultValue{Kind: DefaultString, Value: token}, len(token)
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func hexToBits(hex string) (string, bool) {
+	var sb strings.Builder
+	for _, c := range hex {
+		n, err := strconv.ParseUint(string(c), 16, 8)
+		if err != nil {
+			return "", false
+		}
+		sb.WriteString(strconv.FormatUint(n|0x10, 2)[1:])
+	}
+	return sb.String(), sb.Len() > 0
+}
+
+// parseDMDBIndexColumns 解析主键、唯一约束的列
+func parseDMDBIndexColumns(list string) []*IndexColumn {
+	var columns []*IndexColumn
+	for _, name := range splitDMDBIdentList(list) {
+		columns = append(columns, &IndexColumn{Name: name})
+	}
+	return columns
+}
+
+// parseDMDBIndexExprs 解析索引列，UPPER(col) 还原为 col，SUBSTR(col,1,n) 还原为前缀索引 col(n)，
+// 存在其他函数索引时返回该表达式
+func parseDMDBIndexExprs(list string) ([]*IndexColumn, string) {
+	var columns []*IndexColumn
+	for _, expr := range splitSQL(list, ',') {
+		expr = strings.TrimSpace(expr)
+		inner := expr
+		if m := dmdbUpperRegexp.FindStringSubmatch(inner); m != nil {
+			inner = strings.TrimSpace(m[1])
+		}
+		if m := dmdbSubstrRegexp.FindStringSubmatch(inner); m != nil {
+			columns = append(columns, &IndexColumn{Name: trimDMDBIdent(m[1]), Length: atoiPtr(m[2])})
+			continue
+		}
+		if m := dmdbIndexColRe.FindStringSubmatch(inner); m != nil {
+			columns = append(columns, &IndexColumn{Name: trimDMDBIdent(m[1])})
+			continue
+		}
+		return nil, expr
+	}
+	return columns, ""
+}
+
+func parseDMDBStringList(list string) []string {
+	var values []string
+	for _, v := range splitSQL(list, ',') {
+		values = append(values, unquoteDMDBString(strings.TrimSpace(v)))
+	}
+	return values
+}
+
+// trimDMDBName 去掉 buildIdxName 添加的 <prefix><表名>_ 前缀，不是 myto 生成的名字时原样返回
+func trimDMDBName(prefix, tableName, name string) string {
+	full := prefix + tableName + "_"
+	if len(name) > len(full) && strings.EqualFold(name[:len(full)], full) {
+		return name[len(full):]
+	}
+	return name
+}
+
+// trimDMDBIdent 去掉标识符的引号及模式名
+func trimDMDBIdent(ident string) string {
+	names := splitDMDBIdent(ident)
+	return names[len(names)-1]
+}
+
+// splitDMDBIdent 按 . 拆分带模式名或表名的标识符
+func splitDMDBIdent(ident string) []string {
+	var names []string
+	for _, name := range splitSQL(strings.TrimSpace(ident), '.') {
+		names = append(names, strings.Trim(strings.TrimSpace(name), `"`))
+	}
+	return names
+}
+
+func splitDMDBIdentList(list string) []string {
+	var names []string
+	for _, name := range splitSQL(list, ',') {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			names = append(names, trimDMDBIdent(name))
+		}
+	}
+	return names
+}
+
+func unquoteDMDBString(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		s = s[1 : len(s)-1]
+	}
+	return strings.ReplaceAll(s, "''", "'")
+}
+
+// splitRegexpAlternatives 按未转义的 | 拆分正则表达式中的各个选项，值中的 | 由 regexp.QuoteMeta 转义为 \|
+func splitRegexpAlternatives(s string) []string {
+	var result []string
+	var start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '|':
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, s[start:])
+}
+
+// unquoteRegexp 还原 regexp.QuoteMeta 转义的字符串
+func unquoteRegexp(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func trimParens(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") {
+		if end := matchParen(s, 0); end > 0 {
+			return s[1:end]
+		}
+	}
+	return s
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+func atoiPtr(s string) *int {
+	if len(s) == 0 {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func intPtr(n int) *int {
+	return &n
+}
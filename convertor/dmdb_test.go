@@ -3,6 +3,7 @@ package convertor
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,9 +45,7 @@ func TestOracle_Exec(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			st := sqlparser.NewStringTokenizer(tt.sql)
-
-			o := NewDMDB(st)
+			o := NewDMDB(sqlparser.NewStringTokenizer(tt.sql))
 			got, err := o.Exec()
 			assert.Nil(t, err)
 			assert.NotEqual(t, "", got)
@@ -57,3 +56,629 @@ func TestOracle_Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestDMDB_PrefixIndex(t *testing.T) {
+	sql := "CREATE TABLE `tag` (\n" +
+		"`uuid` varchar(8) NOT NULL,\n" +
+		"`name` varchar(255) NOT NULL,\n" +
+		"`path` varchar(1024) NOT NULL,\n" +
+		"PRIMARY KEY (`uuid`(4)),\n" +
+		"UNIQUE KEY `name` (`uuid`, `name`(16)),\n" +
+		"KEY `tag_path` (`path`(100))\n" +
+		") ENGINE=InnoDB;"
+
+	tests := []struct {
+		name        string
+		strategy    PrefixIndexStrategy
+		contains    []string
+		excludes    []string
+		warnings    int
+		diagnostics int
+	}{
+		{
+			name:     "full column",
+			strategy: PrefixIndexFullColumn,
+			contains: []string{
+				"ALTER TABLE tag ADD CONSTRAINT pk_tag_uuid PRIMARY KEY (uuid);",
+				"CREATE UNIQUE INDEX unq_tag_name ON tag(UPPER(uuid), UPPER(name));",
+				`CREATE INDEX idx_tag_tag_path ON tag("path");`,
+			},
+			warnings:    1,
+			diagnostics: 5,
+		},
+		{
+			name:     "substr",
+			strategy: PrefixIndexSubstr,
+			contains: []string{
+				"ALTER TABLE tag ADD CONSTRAINT pk_tag_uuid PRIMARY KEY (uuid);",
+				"CREATE UNIQUE INDEX unq_tag_name ON tag(UPPER(uuid), UPPER(SUBSTR(name,1,16)));",
+				`CREATE INDEX idx_tag_tag_path ON tag(SUBSTR("path",1,100));`,
+			},
+			warnings:    2,
+			diagnostics: 5,
+		},
+		{
+			name:     "skip",
+			strategy: PrefixIndexSkip,
+			contains: []string{
+				"ALTER TABLE tag ADD CONSTRAINT pk_tag_uuid PRIMARY KEY (uuid);",
+			},
+			excludes:    []string{"unq_tag_name", "idx_tag_tag_path"},
+			warnings:    4,
+			diagnostics: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewDMDBWithOptions(sql, DMDBOptions{PrefixIndex: tt.strategy})
+			got, err := o.Exec()
+			assert.Nil(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, got, s)
+			}
+
+			var warnings int
+			for _, d := range o.Diagnostics() {
+				if d.Level == DiagnosticWarning {
+					warnings++
+				}
+			}
+			assert.Equal(t, tt.warnings, warnings)
+			assert.Equal(t, tt.diagnostics, len(o.Diagnostics()))
+		})
+	}
+}
+
+func TestDMDB_FulltextAndSpatialIndex(t *testing.T) {
+	sql := "CREATE TABLE `article` (\n" +
+		"`uuid` varchar(8) NOT NULL,\n" +
+		"`title` varchar(255) NOT NULL,\n" +
+		"`body` text NOT NULL,\n" +
+		"PRIMARY KEY (`uuid`),\n" +
+		"FULLTEXT KEY `ft_title` (`title`) WITH PARSER ngram,\n" +
+		"FULLTEXT (`body`),\n" +
+		"FULLTEXT INDEX `ft_all` (`title`, `body`)\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "ALTER TABLE article ADD CONSTRAINT pk_article_uuid PRIMARY KEY (uuid);")
+	assert.Contains(t, got, "CREATE CONTEXT INDEX idx_article_ft_title ON article(title) LEXER CHINESE_VGRAM_LEXER;")
+	assert.Contains(t, got, "CREATE CONTEXT INDEX idx_article_body ON article(body);")
+	assert.NotContains(t, got, "ft_all")
+	// 主键列 uuid 按 utf8mb4_general_ci 忽略大小写
+	assert.Equal(t, 2, len(o.Diagnostics()))
+	assert.Equal(t, "PRIMARY", o.Diagnostics()[0].Object)
+	assert.Equal(t, "ft_all", o.Diagnostics()[1].Object)
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{SkipFulltextIndex: true})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.NotContains(t, got, "CONTEXT INDEX")
+	assert.Equal(t, 4, len(o.Diagnostics()))
+
+	shop := &Table{Name: "shop"}
+	spatial := &Index{Name: "sp_location", Kind: IndexSpatial, Columns: []*IndexColumn{{Name: "location"}}}
+	index := &dmdbTableIndex{conv: NewDMDBFromSQL(""), table: shop, Index: spatial}
+	assert.Equal(t, `CREATE SPATIAL INDEX idx_shop_sp_location ON shop("location");`, index.Format())
+
+	index = &dmdbTableIndex{conv: NewDMDBWithOptions("", DMDBOptions{SkipSpatialIndex: true}), table: shop, Index: spatial}
+	assert.Equal(t, "", index.Format())
+	assert.Equal(t, 1, len(index.conv.Diagnostics()))
+}
+
+func TestDMDB_ForeignKey(t *testing.T) {
+	sql := "CREATE TABLE `reply` (\n" +
+		"`uuid` varchar(8) NOT NULL,\n" +
+		"`draft_uuid` varchar(8) NOT NULL,\n" +
+		"`owner` varchar(8) NOT NULL,\n" +
+		"PRIMARY KEY (`uuid`),\n" +
+		"KEY `draft_uuid` (`draft_uuid`),\n" +
+		"CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`) ON DELETE CASCADE ON UPDATE CASCADE,\n" +
+		"FOREIGN KEY (`owner`) REFERENCES `user` (`uuid`) ON DELETE RESTRICT\n" +
+		") ENGINE=InnoDB;\n" +
+		"CREATE TABLE `draft` (\n`uuid` varchar(8) NOT NULL,\nPRIMARY KEY (`uuid`)\n) ENGINE=InnoDB;\n" +
+		"ALTER TABLE `draft` ADD CONSTRAINT `draft_owner` FOREIGN KEY (`uuid`) REFERENCES `user` (`uuid`);\n" +
+		"ALTER TABLE `draft` DROP FOREIGN KEY `draft_owner`, DROP FOREIGN KEY `draft_team`;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+
+	createDraft := strings.Index(got, "CREATE TABLE draft")
+	fk := strings.Index(got, "ALTER TABLE reply ADD CONSTRAINT fk_reply_reply_draft FOREIGN KEY (draft_uuid) REFERENCES draft(uuid) ON DELETE CASCADE;")
+	assert.True(t, createDraft >= 0)
+	assert.True(t, fk > createDraft)
+	assert.Contains(t, got, `ALTER TABLE reply ADD CONSTRAINT fk_reply_reply_ibfk_1 FOREIGN KEY (owner) REFERENCES "user"(uuid);`)
+	assert.Contains(t, got, "ALTER TABLE draft DROP CONSTRAINT fk_draft_draft_team;")
+	assert.NotContains(t, got, "draft_owner")
+
+	var objects []string
+	for _, d := range o.Diagnostics() {
+		objects = append(objects, d.Table+"."+d.Object)
+	}
+	// 两个表的主键列 uuid 按 utf8mb4_general_ci 忽略大小写
+	assert.ElementsMatch(t, []string{"reply.PRIMARY", "reply.reply_draft", "draft.PRIMARY"}, objects)
+}
+
+func TestDMDB_Partition(t *testing.T) {
+	sql := "CREATE TABLE `access_log` (\n" +
+		"`id` bigint(20) NOT NULL,\n" +
+		"`create_time` bigint(20) NOT NULL,\n" +
+		"PRIMARY KEY (`id`, `create_time`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='partition by month'\n" +
+		"/*!50100 PARTITION BY RANGE (`create_time`)\n" +
+		"(PARTITION p202201 VALUES LESS THAN (1643644800) ENGINE = InnoDB,\n" +
+		" PARTITION pmax VALUES LESS THAN MAXVALUE ENGINE = InnoDB) */;\n" +
+		"CREATE TABLE `region` (`id` int NOT NULL, `code` int NOT NULL, PRIMARY KEY (`id`))\n" +
+		"PARTITION BY LIST (`code`) (PARTITION p_east VALUES IN (1, 2), PARTITION p_west VALUES IN (3));\n" +
+		"CREATE TABLE `session` (`id` int NOT NULL, PRIMARY KEY (`id`)) PARTITION BY LINEAR KEY () PARTITIONS 4;\n" +
+		"CREATE TABLE `event` (`id` int NOT NULL, `created` datetime NOT NULL) PARTITION BY RANGE (YEAR(created)) (PARTITION p0 VALUES LESS THAN (2020));\n" +
+		"ALTER TABLE `access_log` ADD PARTITION (PARTITION p202202 VALUES LESS THAN (1646064000));\n" +
+		"ALTER TABLE `access_log` DROP PARTITION p202201, pmax;\n" +
+		"ALTER TABLE `access_log` TRUNCATE PARTITION p202202;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "create_time bigint NOT NULL )\nPARTITION BY RANGE(create_time) (\n"+
+		"PARTITION p202201 VALUES LESS THAN (1643644800),\n"+
+		"PARTITION pmax VALUES LESS THAN (MAXVALUE));\n")
+	assert.Contains(t, got, "COMMENT ON TABLE access_log IS 'partition by month';")
+	assert.Contains(t, got, "PARTITION BY LIST(code) (\nPARTITION p_east VALUES (1, 2),\nPARTITION p_west VALUES (3));")
+	assert.Contains(t, got, "id int NOT NULL )\nPARTITION BY HASH(id) PARTITIONS 4;")
+	assert.Contains(t, got, "created datetime NOT NULL );")
+	assert.Contains(t, got, "ALTER TABLE access_log ADD PARTITION p202202 VALUES LESS THAN (1646064000);")
+	assert.Contains(t, got, "ALTER TABLE access_log DROP PARTITION p202201;\nALTER TABLE access_log DROP PARTITION pmax;")
+	assert.Contains(t, got, "ALTER TABLE access_log TRUNCATE PARTITION p202202;")
+
+	assert.Equal(t, 2, len(o.Diagnostics()))
+	assert.Equal(t, DiagnosticInfo, o.Diagnostics()[0].Level)
+	assert.Equal(t, "event", o.Diagnostics()[1].Table)
+}
+
+func TestDMDB_GeneratedColumn(t *testing.T) {
+	sql := "CREATE TABLE `shop_member` (\n" +
+		"`first_name` varchar(32) NOT NULL,\n" +
+		"`last_name` varchar(32) NOT NULL,\n" +
+		"`full_name` varchar(65) GENERATED ALWAYS AS (concat(`first_name`, ' ', `last_name`)) VIRTUAL COMMENT '全名',\n" +
+		"`price` int NOT NULL,\n" +
+		"`qty` int NOT NULL,\n" +
+		"`total` int AS (`price` * `qty`) STORED NOT NULL\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "full_name varchar2(65 CHAR) GENERATED ALWAYS AS (concat(first_name, ' ', last_name)) VIRTUAL ,\n")
+	assert.Contains(t, got, "total int NOT NULL );\n")
+	assert.Contains(t, got, "COMMENT ON COLUMN shop_member.full_name IS '全名';\n/\n"+
+		"CREATE OR REPLACE TRIGGER trg_shop_member_total\n"+
+		"BEFORE INSERT OR UPDATE ON shop_member\n"+
+		"FOR EACH ROW\n"+
+		"BEGIN\n"+
+		"   :NEW.total := :NEW.price * :NEW.qty;\n"+
+		"END;")
+	assert.Equal(t, 1, len(o.Diagnostics()))
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{StoredGeneratedAsVirtual: true})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "total int GENERATED ALWAYS AS (price * qty) VIRTUAL NOT NULL );\n")
+	assert.NotContains(t, got, "TRIGGER")
+}
+
+func TestDMDB_CharLength(t *testing.T) {
+	sql := "CREATE TABLE `tag` (\n" +
+		"`uuid` varchar(8) CHARACTER SET ascii NOT NULL,\n" +
+		"`name` varchar(128) CHARACTER SET utf8mb4 NOT NULL,\n" +
+		"`code` char(4) CHARACTER SET gbk NOT NULL,\n" +
+		"`note` varchar(10) COLLATE latin1_bin NOT NULL,\n" +
+		"`title` varchar(3000) NOT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "uuid varchar2(8) NOT NULL ,\n")
+	assert.Contains(t, got, "name varchar2(128 CHAR) NOT NULL ,\n")
+	assert.Contains(t, got, "code char(4 CHAR) NOT NULL ,\n")
+	assert.Contains(t, got, "note varchar2(10 CHAR) NOT NULL ,\n")
+	assert.Contains(t, got, "title varchar2(3000 CHAR) NOT NULL );\n")
+	assert.Equal(t, 0, len(o.Diagnostics()))
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{CharLength: CharLengthByte})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "uuid varchar2(8) NOT NULL ,\n")
+	assert.Contains(t, got, "name varchar2(512) NOT NULL ,\n")
+	assert.Contains(t, got, "code char(12) NOT NULL ,\n")
+	assert.Contains(t, got, "note varchar2(30) NOT NULL ,\n")
+	assert.Contains(t, got, "title varchar2(9000) NOT NULL );\n")
+	assert.Equal(t, 1, len(o.Diagnostics()))
+	assert.Equal(t, "title", o.Diagnostics()[0].Object)
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{CharLength: CharLengthByte, TargetCharset: DMCharsetGB18030})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "code char(8) NOT NULL ,\n")
+	assert.Contains(t, got, "note varchar2(20) NOT NULL ,\n")
+}
+
+func TestDMDB_CaseInsensitiveUnique(t *testing.T) {
+	sql := "CREATE TABLE `member_account` (\n" +
+		"`id` varchar(32) NOT NULL,\n" +
+		"`email` varchar(128) NOT NULL,\n" +
+		"`code` varchar(16) COLLATE utf8mb4_bin NOT NULL,\n" +
+		"`org_id` int(11) NOT NULL,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"UNIQUE KEY `email` (`org_id`, `email`),\n" +
+		"UNIQUE KEY `code` (`code`),\n" +
+		"KEY `org_email` (`email`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;"
+
+	tests := []struct {
+		name     string
+		strategy CaseInsensitiveUniqueStrategy
+		contains []string
+		warnings int
+		infos    int
+	}{
+		{
+			name:     "upper",
+			strategy: CaseInsensitiveUpper,
+			contains: []string{
+				"ALTER TABLE member_account ADD CONSTRAINT pk_member_account_id PRIMARY KEY (id);",
+				"CREATE UNIQUE INDEX unq_member_account_email ON member_account(org_id, UPPER(email));",
+				"CREATE UNIQUE INDEX unq_member_account_code ON member_account(code);",
+				"CREATE INDEX idx_member_account_org_email ON member_account(email);",
+			},
+			// 主键无法使用 UPPER()，给出提示
+			warnings: 1,
+			infos:    1,
+		},
+		{
+			name:     "report",
+			strategy: CaseInsensitiveReport,
+			contains: []string{
+				"CREATE UNIQUE INDEX unq_member_account_email ON member_account(org_id, email);",
+				"CREATE UNIQUE INDEX unq_member_account_code ON member_account(code);",
+			},
+			warnings: 2,
+		},
+		{
+			name:     "ignore",
+			strategy: CaseInsensitiveIgnore,
+			contains: []string{
+				"CREATE UNIQUE INDEX unq_member_account_email ON member_account(org_id, email);",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewDMDBWithOptions(sql, DMDBOptions{CaseInsensitiveUnique: tt.strategy})
+			got, err := o.Exec()
+			assert.Nil(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+
+			var warnings, infos int
+			for _, d := range o.Diagnostics() {
+				if d.Level == DiagnosticWarning {
+					warnings++
+				} else {
+					infos++
+				}
+			}
+			assert.Equal(t, tt.warnings, warnings)
+			assert.Equal(t, tt.infos, infos)
+		})
+	}
+}
+
+func TestDMDB_EnumAndSet(t *testing.T) {
+	sql := "CREATE TABLE `shop_order` (\n" +
+		"`state` enum('new','paid','refunded') NOT NULL,\n" +
+		"`flags` set('a.b','it''s','c') DEFAULT NULL,\n" +
+		"`kind` enum('x') CHARACTER SET ascii NOT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "state varchar2(8 CHAR) CONSTRAINT ck_shop_order_state CHECK(state IN ('new', 'paid', 'refunded')) NOT NULL ,\n")
+	assert.Contains(t, got, `flags varchar2(10 CHAR) CONSTRAINT ck_shop_order_flags CHECK(REGEXP_LIKE(flags, '^((a\.b|it''s|c)(,(a\.b|it''s|c))*)?$')) ,`)
+	assert.Contains(t, got, "kind varchar2(1) CONSTRAINT ck_shop_order_kind CHECK(kind IN ('x')) NOT NULL ")
+	assert.Equal(t, 0, len(o.Diagnostics()))
+}
+
+func TestDMDB_JSONConstraint(t *testing.T) {
+	sql := "CREATE TABLE `doc` (\n" +
+		"`body` json NOT NULL,\n" +
+		"`meta` json DEFAULT NULL\n" +
+		") ENGINE=InnoDB;\n" +
+		"CREATE TABLE `doc_history` (\n" +
+		"`body` json NOT NULL\n" +
+		") ENGINE=InnoDB;"
+
+	// 多次转换的结果应一致
+	for i := 0; i < 2; i++ {
+		got, err := NewDMDBFromSQL(sql).Exec()
+		assert.Nil(t, err)
+		assert.Contains(t, got, "body text CONSTRAINT ck_doc_body_json CHECK (body IS JSON) NOT NULL ,\n")
+		assert.Contains(t, got, "meta text CONSTRAINT ck_doc_meta_json CHECK (meta IS JSON) ")
+		assert.Contains(t, got, "body text CONSTRAINT ck_doc_history_body_json CHECK (body IS JSON) NOT NULL ")
+	}
+}
+
+func TestDMDB_TinyintBool(t *testing.T) {
+	sql := "CREATE TABLE `task` (\n" +
+		"`is_done` tinyint(1) NOT NULL DEFAULT '0',\n" +
+		"`is_public` tinyint(1) DEFAULT 1,\n" +
+		"`flag` tinyint(1) NOT NULL DEFAULT '2',\n" +
+		"`priority` tinyint(4) NOT NULL DEFAULT '3',\n" +
+		"`progress` tinyint(3) unsigned NOT NULL DEFAULT '0',\n" +
+		"`deleted` tinyint(1) NOT NULL DEFAULT b'1'\n" +
+		") ENGINE=InnoDB;"
+
+	tests := []struct {
+		name      string
+		options   DMDBOptions
+		contains  []string
+		diagCount int
+	}{
+		{
+			name: "bit",
+			contains: []string{
+				"is_done bit DEFAULT 0 NOT NULL ,\n",
+				"is_public bit DEFAULT 1 ,\n",
+				"flag bit DEFAULT 1 NOT NULL ,\n",
+				"priority tinyint NOT NULL ,\n",
+				"progress smallint NOT NULL ,\n",
+				"deleted bit DEFAULT 1 NOT NULL );\n",
+			},
+			diagCount: 1,
+		},
+		{
+			name:    "boolean",
+			options: DMDBOptions{TinyintBool: TinyintBoolBoolean},
+			contains: []string{
+				"is_done boolean DEFAULT 0 NOT NULL ,\n",
+				"priority tinyint NOT NULL ,\n",
+			},
+			diagCount: 1,
+		},
+		{
+			name: "overrides",
+			options: DMDBOptions{TinyintBoolOverrides: map[string]TinyintBoolMapping{
+				"TASK":      TinyintBoolBoolean,
+				"task.flag": TinyintBoolNumber,
+			}},
+			contains: []string{
+				"is_done boolean DEFAULT 0 NOT NULL ,\n",
+				"flag tinyint NOT NULL ,\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewDMDBWithOptions(sql, tt.options)
+			got, err := o.Exec()
+			assert.Nil(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+			assert.Equal(t, tt.diagCount, len(o.Diagnostics()))
+		})
+	}
+}
+
+func TestDMDB_TinyintBoolLiteralDefault(t *testing.T) {
+	sql := "CREATE TABLE `todo` (\n" +
+		"`is_done` tinyint(1) NOT NULL DEFAULT TRUE,\n" +
+		"`is_public` bool DEFAULT false COMMENT 'DEFAULT TRUE',\n" +
+		"`archived` boolean NOT NULL default True\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "is_done bit DEFAULT 1 NOT NULL ,\n")
+	assert.Contains(t, got, "is_public bit DEFAULT 0 ,\n")
+	assert.Contains(t, got, "archived bit DEFAULT 1 NOT NULL );\n")
+	assert.Contains(t, got, "COMMENT ON COLUMN todo.is_public IS 'DEFAULT TRUE';")
+	assert.Empty(t, o.Diagnostics())
+}
+
+func TestDMDB_BitAndBinary(t *testing.T) {
+	sql := "CREATE TABLE `device` (\n" +
+		"`enabled` bit(1) NOT NULL DEFAULT b'1',\n" +
+		"`mask` bit(8) NOT NULL DEFAULT b'0101',\n" +
+		"`flags` bit(64) DEFAULT NULL,\n" +
+		"`uid` binary(16) NOT NULL DEFAULT x'00ab',\n" +
+		"`token` varbinary(255) DEFAULT 0x1F,\n" +
+		"`code` binary(4) NOT NULL DEFAULT 0x0A,\n" +
+		"`stamp` binary(8) DEFAULT CURRENT_TIMESTAMP,\n" +
+		"`payload` varbinary(10000) DEFAULT NULL\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "enabled bit DEFAULT 1 NOT NULL ,\n")
+	assert.Contains(t, got, "mask binary(1) DEFAULT 0x05 NOT NULL ,\n")
+	assert.Contains(t, got, "flags binary(8) DEFAULT NULL ,\n")
+	// binary(n) 与 mysql 一致在右侧补 0x00
+	assert.Contains(t, got, "uid binary(16) DEFAULT 0x00AB"+strings.Repeat("00", 14)+" NOT NULL ,\n")
+	assert.Contains(t, got, "token varbinary(255) DEFAULT 0x1F ,\n")
+	assert.Contains(t, got, "code binary(4) DEFAULT 0x0A000000 NOT NULL ,\n")
+	assert.Contains(t, got, "stamp binary(8) ,\n")
+	assert.Contains(t, got, "payload longvarbinary DEFAULT NULL );\n")
+	assert.Equal(t, 2, len(o.Diagnostics()))
+	assert.Equal(t, "[WARN] device.stamp: default value CURRENT_TIMESTAMP of binary column can not be converted, skipped", o.Diagnostics()[0].String())
+	assert.Equal(t, "payload", o.Diagnostics()[1].Object)
+}
+
+func TestDMDB_SpatialType(t *testing.T) {
+	sql := "CREATE TABLE `shop_store` (\n" +
+		"`id` int(11) NOT NULL,\n" +
+		"`geo` POINT NOT NULL SRID 4326,\n" +
+		"`area` polygon DEFAULT NULL,\n" +
+		"`routes` multilinestring DEFAULT NULL,\n" +
+		"`shape` geometrycollection DEFAULT NULL,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"SPATIAL KEY `geo` (`geo`)\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "geo SYSGEO.ST_POINT CONSTRAINT ck_shop_store_geo_srid CHECK (DMGEO.ST_SRID(geo) = 4326) NOT NULL ,\n")
+	assert.Contains(t, got, "area SYSGEO.ST_POLYGON ,\n")
+	assert.Contains(t, got, "routes SYSGEO.ST_MULTILINESTRING ,\n")
+	assert.Contains(t, got, "shape SYSGEO.ST_GEOMCOLLECTION );\n")
+	assert.Contains(t, got, "CREATE SPATIAL INDEX idx_shop_store_geo ON shop_store(geo);")
+	assert.Equal(t, 0, len(o.Diagnostics()))
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{SpatialType: SpatialWKB})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "geo blob NOT NULL ,\n")
+	assert.Contains(t, got, "area blob ,\n")
+	assert.NotContains(t, got, "SPATIAL INDEX")
+	assert.Equal(t, 2, len(o.Diagnostics()))
+}
+
+func TestDMDB_NumericPrecision(t *testing.T) {
+	sql := "CREATE TABLE `invoice` (\n" +
+		"`amount` decimal NOT NULL,\n" +
+		"`rate` decimal(5) DEFAULT NULL,\n" +
+		"`price` decimal(12,4) unsigned NOT NULL DEFAULT '0.0000',\n" +
+		"`weight` float DEFAULT NULL,\n" +
+		"`ratio` float(30) DEFAULT NULL,\n" +
+		"`score` float(7,2) DEFAULT NULL,\n" +
+		"`total` double DEFAULT NULL,\n" +
+		"`tax` double(16,2) unsigned DEFAULT NULL\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "amount numeric(10,0) NOT NULL ,\n")
+	assert.Contains(t, got, "rate numeric(5,0) ,\n")
+	assert.Contains(t, got, "price numeric(12,4) CONSTRAINT ck_invoice_price_unsigned CHECK (price >= 0) NOT NULL ,\n")
+	assert.Contains(t, got, "weight real ,\n")
+	assert.Contains(t, got, "ratio double ,\n")
+	assert.Contains(t, got, "score number(7,2) ,\n")
+	assert.Contains(t, got, "total double ,\n")
+	assert.Contains(t, got, "tax number(16,2) CONSTRAINT ck_invoice_tax_unsigned CHECK (tax >= 0) );\n")
+	assert.Equal(t, 0, len(o.Diagnostics()))
+}
+
+func TestDMDB_TextAndBlob(t *testing.T) {
+	sql := "CREATE TABLE `attachment` (\n" +
+		"`summary` tinytext,\n" +
+		"`content` text NOT NULL,\n" +
+		"`detail` mediumtext,\n" +
+		"`raw` longtext,\n" +
+		"`thumb` tinyblob,\n" +
+		"`preview` blob,\n" +
+		"`image` mediumblob,\n" +
+		"`file` longblob\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "summary text ,\n")
+	assert.Contains(t, got, "content text NOT NULL ,\n")
+	assert.Contains(t, got, "detail text ,\n")
+	assert.Contains(t, got, "raw clob ,\n")
+	assert.Contains(t, got, "thumb varbinary(255) ,\n")
+	assert.Contains(t, got, "preview blob ,\n")
+	assert.Contains(t, got, "\"image\" blob ,\n")
+	assert.Contains(t, got, "\"file\" blob );\n")
+	assert.Equal(t, 0, len(o.Diagnostics()))
+
+	o = NewDMDBWithOptions(sql, DMDBOptions{TextAsVarchar: true})
+	got, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "summary varchar2(255 CHAR) ,\n")
+	assert.Contains(t, got, "content text NOT NULL ,\n")
+	assert.Equal(t, 1, len(o.Diagnostics()))
+	assert.Equal(t, "content", o.Diagnostics()[0].Object)
+}
+
+func TestNewDMDB_Tokenizer(t *testing.T) {
+	sql := "CREATE TABLE `tag` (`uuid` varchar(8) NOT NULL COMMENT 'uuid', PRIMARY KEY (`uuid`));\n" +
+		"CREATE TABLE `bad` (`id` int, CONSTRAINT c CHECK (id > 0));"
+
+	o := NewDMDB(sqlparser.NewStringTokenizer(sql))
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "CREATE TABLE tag (\nuuid varchar2(8 CHAR) NOT NULL );")
+	assert.Contains(t, got, "COMMENT ON COLUMN tag.uuid IS 'uuid';")
+	assert.NotContains(t, got, "bad")
+	assert.Equal(t, 2, len(o.Diagnostics()))
+}
+
+func TestDMDB_DiagnosticsPerRun(t *testing.T) {
+	sql := "CREATE TABLE `article` (`id` int NOT NULL, `title` varchar(64), PRIMARY KEY (`id`), FULLTEXT KEY `ft_title` (`title`));"
+
+	o := NewDMDBWithOptions(sql, DMDBOptions{SkipFulltextIndex: true})
+	_, err := o.Exec()
+	assert.Nil(t, err)
+	first := o.Diagnostics()
+	assert.NotEmpty(t, first)
+
+	// 同一个 DMDB 再次转换时不重复给出提示
+	_, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, first, o.Diagnostics())
+	_, err = o.Objects()
+	assert.Nil(t, err)
+	assert.Equal(t, first, o.Diagnostics())
+
+	// NewDMDB 读取 sqlTokenizer 时的提示每次转换都会给出
+	o = NewDMDB(sqlparser.NewStringTokenizer("CREATE TABLE `t` (`id` int);\nLOCK TABLES `t` WRITE;"))
+	_, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Len(t, o.Diagnostics(), 1)
+	_, err = o.Exec()
+	assert.Nil(t, err)
+	assert.Len(t, o.Diagnostics(), 1)
+}
+
+func TestDMDB_UnmappedColumnType(t *testing.T) {
+	sql := "CREATE TABLE `event` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`happened_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '发生时间',\n" +
+		"`duration` time DEFAULT NULL,\n" +
+		"`season` year DEFAULT NULL,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"KEY `idx_happened_at` (`happened_at`)\n" +
+		") ENGINE=InnoDB;"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "CREATE TABLE event (\nid bigint NOT NULL );")
+	assert.Contains(t, got, "ALTER TABLE event ADD CONSTRAINT")
+	assert.NotContains(t, got, "happened_at")
+
+	var messages []string
+	for _, d := range o.Diagnostics() {
+		messages = append(messages, d.String())
+	}
+	assert.Equal(t, []string{
+		"[WARN] event.happened_at: column type 'timestamp' has no DM mapping, column skipped, add it to the type mapping to convert it",
+		"[WARN] event.duration: column type 'time' has no DM mapping, column skipped, add it to the type mapping to convert it",
+		"[WARN] event.season: column type 'year' has no DM mapping, column skipped, add it to the type mapping to convert it",
+		"[WARN] event.idx_happened_at: index skipped, column happened_at is skipped",
+	}, messages)
+}
@@ -0,0 +1,56 @@
+package convertor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var flywayDescriptionRegexp = regexp.MustCompile(`[^0-9A-Za-z_]+`)
+
+// FlywayScript 一个 Flyway 版本化脚本
+type FlywayScript struct {
+	Version     int
+	Description string // 如 create_draft，只含字母、数字及下划线
+	SQL         string
+}
+
+// FileName 按 Flyway 的命名规则返回文件名，如 V1__create_draft.sql
+func (s *FlywayScript) FileName() string {
+	return fmt.Sprintf("V%d__%s.sql", s.Version, s.Description)
+}
+
+// FlywayScripts 将转换结果拆分为 Flyway 版本化脚本，版本号从 startVersion 开始递增。
+// 建表语句连同其主键、索引、注释及触发器为一个脚本，删除表为一个脚本，同一个表连续的外键、分区操作为一个脚本
+func FlywayScripts(objects []*DMDBObject, startVersion int) []*FlywayScript {
+	var scripts []*FlywayScript
+	var statements []string
+	flush := func() {
+		if len(scripts) > 0 {
+			scripts[len(scripts)-1].SQL = strings.Join(statements, "\n/\n") + "\n"
+		}
+		statements = nil
+	}
+
+	for _, object := range objects {
+		var description string
+		switch object.Kind {
+		case DMDBObjectTable:
+			description = "create_" + object.Table
+		case DMDBObjectDropTable:
+			description = "drop_" + object.Table
+		case DMDBObjectForeignKey, DMDBObjectDropForeignKey, DMDBObjectPartition:
+			description = "alter_" + object.Table
+		}
+		description = strings.Trim(flywayDescriptionRegexp.ReplaceAllString(description, "_"), "_")
+
+		// 主键、索引、注释及触发器属于前面的建表语句
+		if len(description) > 0 && (len(scripts) == 0 || object.Kind == DMDBObjectTable || scripts[len(scripts)-1].Description != description) {
+			flush()
+			scripts = append(scripts, &FlywayScript{Version: startVersion + len(scripts), Description: description})
+		}
+		statements = append(statements, object.SQL)
+	}
+	flush()
+	return scripts
+}
@@ -0,0 +1,35 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlywayScripts(t *testing.T) {
+	sql := "DROP TABLE IF EXISTS `reply`;\n" +
+		"CREATE TABLE `reply` (`id` bigint NOT NULL, `draft_uuid` varchar(8) NOT NULL COMMENT '草稿', PRIMARY KEY (`id`), " +
+		"KEY `idx_draft` (`draft_uuid`), CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`));\n" +
+		"CREATE TABLE `draft` (`uuid` varchar(8) NOT NULL);"
+
+	objects, err := NewDMDBFromSQL(sql).Objects()
+	assert.Nil(t, err)
+	var kinds []DMDBObjectKind
+	for _, object := range objects {
+		kinds = append(kinds, object.Kind)
+	}
+	assert.Equal(t, []DMDBObjectKind{DMDBObjectDropTable, DMDBObjectTable, DMDBObjectPrimaryKey, DMDBObjectIndex,
+		DMDBObjectComment, DMDBObjectTable, DMDBObjectForeignKey}, kinds)
+
+	scripts := FlywayScripts(objects, 3)
+	var names []string
+	for _, script := range scripts {
+		names = append(names, script.FileName())
+	}
+	assert.Equal(t, []string{"V3__drop_reply.sql", "V4__create_reply.sql", "V5__create_draft.sql", "V6__alter_reply.sql"}, names)
+	assert.Equal(t, "CREATE TABLE reply (\nid bigint NOT NULL ,\ndraft_uuid varchar2(8 CHAR) NOT NULL );\n/\n"+
+		"ALTER TABLE reply ADD CONSTRAINT pk_reply_id PRIMARY KEY (id);\n/\n"+
+		"CREATE INDEX idx_reply_idx_draft ON reply(draft_uuid);\n/\n"+
+		"COMMENT ON COLUMN reply.draft_uuid IS '草稿';\n", scripts[1].SQL)
+	assert.Equal(t, "ALTER TABLE reply ADD CONSTRAINT fk_reply_reply_draft FOREIGN KEY (draft_uuid) REFERENCES draft(uuid);\n", scripts[3].SQL)
+}
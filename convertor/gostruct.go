@@ -0,0 +1,230 @@
+package convertor
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 字段名中按全大写输出的缩写
+var goInitialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TCP": true, "UDP": true, "UI": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// GoStructOptions 生成 Go 结构体的选项
+type GoStructOptions struct {
+	// Package 生成文件的包名，为空时为 model
+	Package string
+	// Tags 字段的 tag，为空时为 gorm 和 db（sqlx）。gorm 之外的 tag 的值为列名
+	Tags []string
+}
+
+func (o GoStructOptions) packageName() string {
+	if len(o.Package) > 0 {
+		return o.Package
+	}
+	return "model"
+}
+
+func (o GoStructOptions) tags() []string {
+	if len(o.Tags) > 0 {
+		return o.Tags
+	}
+	return []string{"gorm", "db"}
+}
+
+// GoStructs 为 CREATE TABLE 的表生成 Go 结构体，字段类型按转换后的达梦类型选择，可为 NULL 的列使用 sql.Null* 类型，
+// 定点数使用字符串以免丢失精度，表、列注释作为结构体及字段的注释
+func (o *DMDB) GoStructs(options GoStructOptions) (string, error) {
+	o.resetDiagnostics()
+	schema := o.parseSchema(&o.diagnostics)
+	imports := map[string]bool{}
+
+	var body strings.Builder
+	for _, table := range schema.Tables() {
+		body.WriteString("\n")
+		o.formatGoStruct(&body, table, options, imports)
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "// Code generated by myto. DO NOT EDIT.\n\npackage %s\n", options.packageName())
+	if len(imports) > 0 {
+		var paths []string
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		sb.WriteString("\nimport (\n")
+		for _, path := range paths {
+			_, _ = fmt.Fprintf(&sb, "\t%q\n", path)
+		}
+		sb.WriteString(")\n")
+	}
+	sb.WriteString(body.String())
+
+	source, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(source), nil
+}
+
+func (o *DMDB) formatGoStruct(sb *strings.Builder, table *Table, options GoStructOptions, imports map[string]bool) {
+	structName := goName(table.Name)
+	writeGoComment(sb, "", structName, table.Comment)
+	_, _ = fmt.Fprintf(sb, "type %s struct {\n", structName)
+
+	primaryKey := map[string]bool{}
+	if pk := table.PrimaryKey(); pk != nil {
+		for _, name := range pk.ColumnNames() {
+			primaryKey[strings.ToLower(name)] = true
+		}
+	}
+	fieldNames := map[string]int{}
+	for _, column := range table.Columns {
+		fieldName := goName(column.Name)
+		if n := fieldNames[fieldName]; n > 0 {
+			fieldNames[fieldName]++
+			fieldName = fmt.Sprintf("%s%d", fieldName, n+1)
+		} else {
+			fieldNames[fieldName] = 1
+		}
+
+		goType, importPath := o.goType(table, column, primaryKey[strings.ToLower(column.Name)])
+		if len(importPath) > 0 {
+			imports[importPath] = true
+		}
+
+		var tags []string
+		for _, tag := range options.tags() {
+			value := column.Name
+			if tag == "gorm" {
+				value = goGormTag(column, primaryKey[strings.ToLower(column.Name)])
+			}
+			tags = append(tags, fmt.Sprintf("%s:%q", tag, value))
+		}
+
+		writeGoComment(sb, "\t", "", column.Comment)
+		_, _ = fmt.Fprintf(sb, "\t%s %s `%s`\n", fieldName, goType, strings.Join(tags, " "))
+	}
+	sb.WriteString("}\n")
+
+	for _, tag := range options.tags() {
+		if tag == "gorm" {
+			_, _ = fmt.Fprintf(sb, "\n// TableName 返回表名，避免 gorm 按结构体名推断\nfunc (%s) TableName() string {\n\treturn %q\n}\n", structName, table.Name)
+		}
+	}
+}
+
+// goType 返回列的 Go 类型及需要导入的包，主键列不为 NULL
+func (o *DMDB) goType(table *Table, column *Column, primaryKey bool) (string, string) {
+	scratch := &DMDB{options: o.options}
+	tableColumn, _ := newDMDBTableColumn(scratch, table, column)
+	typeName, found := tableColumn.typeName()
+	if !found {
+		o.diagnostics.Warnf(table.Name, column.Name, "column type '%s' has no DM mapping, string used", column.Type.Name)
+	}
+
+	var goType, nullType string
+	switch name := strings.ToLower(typeName); {
+	case name == "bit" || name == "boolean":
+		goType, nullType = "bool", "sql.NullBool"
+	case name == "tinyint" || name == "smallint" || name == "int" || name == "integer":
+		goType, nullType = "int32", "sql.NullInt32"
+	case name == "bigint":
+		goType, nullType = "int64", "sql.NullInt64"
+	case name == "real" || name == "float" || name == "double":
+		goType, nullType = "float64", "sql.NullFloat64"
+	case name == "numeric" || name == "number" || name == "decimal" || name == "dec":
+		goType, nullType = "string", "sql.NullString"
+	case name == "date" || name == "datetime" || name == "timestamp" || name == "time":
+		goType, nullType = "time.Time", "sql.NullTime"
+	case name == "char" || name == "varchar" || name == "varchar2" || name == "text" || name == "clob":
+		goType, nullType = "string", "sql.NullString"
+	case name == "binary" || name == "varbinary" || name == "longvarbinary" || name == "blob" || name == "image" ||
+		strings.HasPrefix(name, "sysgeo."):
+		// NULL 读取为 nil
+		return "[]byte", ""
+	default:
+		if found {
+			o.diagnostics.Warnf(table.Name, column.Name, "no go type for DM type '%s', string used", typeName)
+		}
+		goType, nullType = "string", "sql.NullString"
+	}
+
+	if !column.NotNull && !primaryKey && !column.AutoIncrement {
+		return nullType, "database/sql"
+	}
+	if goType == "time.Time" {
+		return goType, "time"
+	}
+	return goType, ""
+}
+
+// goGormTag 输出 gorm 的列名、主键、自增，生成列只读
+func goGormTag(column *Column, primaryKey bool) string {
+	values := []string{"column:" + column.Name}
+	if primaryKey {
+		values = append(values, "primaryKey")
+	}
+	if column.AutoIncrement {
+		values = append(values, "autoIncrement")
+	}
+	if column.Generated != nil {
+		values = append(values, "->")
+	}
+	return strings.Join(values, ";")
+}
+
+// goName 将表名、列名转换为导出的 Go 标识符，如 team_uuid、teamUuid 转换为 TeamUUID
+func goName(name string) string {
+	var sb strings.Builder
+	for _, word := range goWords(name) {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	result := sb.String()
+	if len(result) == 0 || !unicode.IsLetter([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+// goWords 按非字母数字的字符及小写到大写的变化拆分单词
+func goWords(name string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(field)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i]) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// writeGoComment 输出注释，多行注释合并为一行
+func writeGoComment(sb *strings.Builder, indent, name, comment string) {
+	comment = strings.Join(strings.Fields(comment), " ")
+	switch {
+	case len(comment) > 0 && len(name) > 0:
+		_, _ = fmt.Fprintf(sb, "%s// %s %s\n", indent, name, comment)
+	case len(comment) > 0:
+		_, _ = fmt.Fprintf(sb, "%s// %s\n", indent, comment)
+	}
+}
@@ -0,0 +1,60 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDMDB_GoStructs(t *testing.T) {
+	sql := "CREATE TABLE `team_member` (\n" +
+		"`id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"`team_uuid` varchar(8) NOT NULL COMMENT '团队',\n" +
+		"`nickname` varchar(64) DEFAULT NULL,\n" +
+		"`salary` decimal(10,2) NOT NULL,\n" +
+		"`is_admin` tinyint(1) NOT NULL DEFAULT '0',\n" +
+		"`score` double,\n" +
+		"`joined_at` datetime,\n" +
+		"`avatar` blob,\n" +
+		"`name_len` int GENERATED ALWAYS AS (char_length(`nickname`)) VIRTUAL,\n" +
+		"PRIMARY KEY (`id`)\n" +
+		") COMMENT='团队成员';"
+
+	o := NewDMDBFromSQL(sql)
+	got, err := o.GoStructs(GoStructOptions{Package: "dao"})
+	assert.Nil(t, err)
+	assert.Equal(t, "// Code generated by myto. DO NOT EDIT.\n"+
+		"\n"+
+		"package dao\n"+
+		"\n"+
+		"import (\n"+
+		"\t\"database/sql\"\n"+
+		")\n"+
+		"\n"+
+		"// TeamMember 团队成员\n"+
+		"type TeamMember struct {\n"+
+		"\tID int64 `gorm:\"column:id;primaryKey;autoIncrement\" db:\"id\"`\n"+
+		"\t// 团队\n"+
+		"\tTeamUUID string          `gorm:\"column:team_uuid\" db:\"team_uuid\"`\n"+
+		"\tNickname sql.NullString  `gorm:\"column:nickname\" db:\"nickname\"`\n"+
+		"\tSalary   string          `gorm:\"column:salary\" db:\"salary\"`\n"+
+		"\tIsAdmin  bool            `gorm:\"column:is_admin\" db:\"is_admin\"`\n"+
+		"\tScore    sql.NullFloat64 `gorm:\"column:score\" db:\"score\"`\n"+
+		"\tJoinedAt sql.NullTime    `gorm:\"column:joined_at\" db:\"joined_at\"`\n"+
+		"\tAvatar   []byte          `gorm:\"column:avatar\" db:\"avatar\"`\n"+
+		"\tNameLen  sql.NullInt32   `gorm:\"column:name_len;->\" db:\"name_len\"`\n"+
+		"}\n"+
+		"\n"+
+		"// TableName 返回表名，避免 gorm 按结构体名推断\n"+
+		"func (TeamMember) TableName() string {\n"+
+		"\treturn \"team_member\"\n"+
+		"}\n", got)
+	assert.Empty(t, o.Diagnostics())
+}
+
+func Test_goName(t *testing.T) {
+	assert.Equal(t, "TeamUUID", goName("team_uuid"))
+	assert.Equal(t, "UserID", goName("userId"))
+	assert.Equal(t, "X2fa", goName("2fa"))
+	assert.Equal(t, "OrderItems", goName("order-items"))
+}
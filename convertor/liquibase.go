@@ -0,0 +1,134 @@
+package convertor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LiquibaseFormat Liquibase changelog 的格式
+type LiquibaseFormat int
+
+const (
+	LiquibaseXML LiquibaseFormat = iota
+	LiquibaseYAML
+)
+
+var liquibaseFormatNames = map[LiquibaseFormat]string{
+	LiquibaseXML:  "xml",
+	LiquibaseYAML: "yaml",
+}
+
+func (f LiquibaseFormat) String() string {
+	if name, found := liquibaseFormatNames[f]; found {
+		return name
+	}
+	return fmt.Sprintf("LiquibaseFormat(%d)", int(f))
+}
+
+// ParseLiquibaseFormat 将 xml/yaml 解析为对应的格式
+func ParseLiquibaseFormat(name string) (LiquibaseFormat, error) {
+	for f, n := range liquibaseFormatNames {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown liquibase format '%s'", name)
+}
+
+type liquibaseXMLChangelog struct {
+	XMLName        xml.Name                `xml:"databaseChangeLog"`
+	Xmlns          string                  `xml:"xmlns,attr"`
+	XmlnsXsi       string                  `xml:"xmlns:xsi,attr"`
+	SchemaLocation string                  `xml:"xsi:schemaLocation,attr"`
+	ChangeSets     []liquibaseXMLChangeSet `xml:"changeSet"`
+}
+
+type liquibaseXMLChangeSet struct {
+	ID     string          `xml:"id,attr"`
+	Author string          `xml:"author,attr"`
+	SQL    liquibaseXMLSQL `xml:"sql"`
+}
+
+type liquibaseXMLSQL struct {
+	SplitStatements string `xml:"splitStatements,attr,omitempty"`
+	SQL             string `xml:",cdata"`
+}
+
+type liquibaseYAMLChangelog struct {
+	DatabaseChangeLog []liquibaseYAMLEntry `yaml:"databaseChangeLog"`
+}
+
+type liquibaseYAMLEntry struct {
+	ChangeSet liquibaseYAMLChangeSet `yaml:"changeSet"`
+}
+
+type liquibaseYAMLChangeSet struct {
+	ID      string                `yaml:"id"`
+	Author  string                `yaml:"author"`
+	Changes []liquibaseYAMLChange `yaml:"changes"`
+}
+
+type liquibaseYAMLChange struct {
+	SQL liquibaseYAMLSQL `yaml:"sql"`
+}
+
+type liquibaseYAMLSQL struct {
+	SplitStatements *bool  `yaml:"splitStatements,omitempty"`
+	SQL             string `yaml:"sql"`
+}
+
+// LiquibaseChangelog 将转换结果输出为 Liquibase changelog，每个表、索引、注释等对象一个 changeSet。
+// BEGIN ... END 块及触发器不按 ; 拆分语句
+func LiquibaseChangelog(objects []*DMDBObject, format LiquibaseFormat, author string) (string, error) {
+	var xmlChangelog = liquibaseXMLChangelog{
+		Xmlns:          "http://www.liquibase.org/xml/ns/dbchangelog",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-latest.xsd",
+	}
+	var yamlChangelog = liquibaseYAMLChangelog{DatabaseChangeLog: []liquibaseYAMLEntry{}}
+
+	// changeSet 的 id 只由对象决定，增删其他对象后已执行的 changeSet 的 id 不变，重复时依次添加 -2、-3 后缀
+	var ids = map[string]struct{}{}
+	for _, object := range objects {
+		base := fmt.Sprintf("%s-%s", object.Kind, object.Table)
+		if len(object.Name) > 0 {
+			base += "-" + object.Name
+		}
+		id := base
+		for n := 2; ; n++ {
+			if _, found := ids[id]; !found {
+				break
+			}
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		ids[id] = struct{}{}
+		block := dmdbBlockRegexp.MatchString(object.SQL)
+
+		xmlChangeSet := liquibaseXMLChangeSet{ID: id, Author: author, SQL: liquibaseXMLSQL{SQL: object.SQL}}
+		yamlChangeSet := liquibaseYAMLChangeSet{ID: id, Author: author, Changes: []liquibaseYAMLChange{{SQL: liquibaseYAMLSQL{SQL: object.SQL}}}}
+		if block {
+			xmlChangeSet.SQL.SplitStatements = "false"
+			yamlChangeSet.Changes[0].SQL.SplitStatements = new(bool)
+		}
+		xmlChangelog.ChangeSets = append(xmlChangelog.ChangeSets, xmlChangeSet)
+		yamlChangelog.DatabaseChangeLog = append(yamlChangelog.DatabaseChangeLog, liquibaseYAMLEntry{ChangeSet: yamlChangeSet})
+	}
+
+	if format == LiquibaseYAML {
+		var sb strings.Builder
+		encoder := yaml.NewEncoder(&sb)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&yamlChangelog); err != nil {
+			return "", err
+		}
+		return sb.String(), encoder.Close()
+	}
+	data, err := xml.MarshalIndent(&xmlChangelog, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data) + "\n", nil
+}
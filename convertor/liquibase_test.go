@@ -0,0 +1,41 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiquibaseChangelog(t *testing.T) {
+	objects, err := NewDMDBFromSQL("CREATE TABLE `g` (`a` int COMMENT 'a<b', `b` int GENERATED ALWAYS AS (a+1) STORED);").Objects()
+	assert.Nil(t, err)
+
+	got, err := LiquibaseChangelog(objects, LiquibaseXML, "dba")
+	assert.Nil(t, err)
+	assert.Contains(t, got, "<changeSet id=\"table-g\" author=\"dba\">\n    <sql><![CDATA[CREATE TABLE g (\na int ,\nb int );]]></sql>\n  </changeSet>")
+	assert.Contains(t, got, "<changeSet id=\"comment-g-a\" author=\"dba\">\n    <sql><![CDATA[COMMENT ON COLUMN g.a IS 'a<b';]]></sql>")
+	assert.Contains(t, got, "<changeSet id=\"trigger-g-b\" author=\"dba\">\n    <sql splitStatements=\"false\"><![CDATA[CREATE OR REPLACE TRIGGER trg_g_b\n")
+
+	got, err = LiquibaseChangelog(objects, LiquibaseYAML, "dba")
+	assert.Nil(t, err)
+	assert.Contains(t, got, "databaseChangeLog:\n  - changeSet:\n      id: table-g\n      author: dba\n      changes:\n        - sql:\n            sql: |-\n              CREATE TABLE g (\n")
+	assert.Contains(t, got, "      id: trigger-g-b\n      author: dba\n      changes:\n        - sql:\n            splitStatements: false\n")
+
+	// 删除外键及重复的 id
+	objects, err = NewDMDBFromSQL("ALTER TABLE `g` DROP FOREIGN KEY `g_ibfk_1`;\n" +
+		"ALTER TABLE `g` DROP PARTITION p0;\n" +
+		"ALTER TABLE `g` DROP PARTITION p1;").Objects()
+	assert.Nil(t, err)
+	assert.Equal(t, DMDBObjectDropForeignKey, objects[0].Kind)
+	got, err = LiquibaseChangelog(objects, LiquibaseXML, "dba")
+	assert.Nil(t, err)
+	assert.Contains(t, got, "<changeSet id=\"drop_foreign_key-g-g_ibfk_1\" author=\"dba\">")
+	assert.Contains(t, got, "<changeSet id=\"partition-g\" author=\"dba\">")
+	assert.Contains(t, got, "<changeSet id=\"partition-g-2\" author=\"dba\">")
+
+	format, err := ParseLiquibaseFormat("YAML")
+	assert.Nil(t, err)
+	assert.Equal(t, LiquibaseYAML, format)
+	_, err = ParseLiquibaseFormat("json")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,139 @@
+package convertor
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gooseDirectivePrefix goose 迁移文件中指令注释的前缀
+const gooseDirectivePrefix = "-- +goose"
+
+// MigrationFile 迁移目录中一个文件的转换结果
+type MigrationFile struct {
+	Path        string // 相对于迁移目录的路径
+	Diagnostics []Diagnostic
+}
+
+// SkippedStatements 返回未被转换的语句或操作的提示，不为空时转换得到的文件缺少原文件中的内容
+func (f *MigrationFile) SkippedStatements() []Diagnostic {
+	var skipped []Diagnostic
+	for _, d := range f.Diagnostics {
+		if d.Skipped {
+			skipped = append(skipped, d)
+		}
+	}
+	return skipped
+}
+
+// ConvertMigration 转换一个 mysql 迁移文件。含有 goose 指令的文件逐段转换并保留 Up、Down 等指令，
+// 转换得到的语句块用 StatementBegin、StatementEnd 包裹；其他文件（如 golang-migrate 的 .up.sql、.down.sql）整体转换
+func ConvertMigration(content string, options DMDBOptions) (string, []Diagnostic, error) {
+	if !isGooseMigration(content) {
+		conv := NewDMDBWithOptions(content, options)
+		output, err := conv.Exec()
+		if err != nil {
+			return "", nil, err
+		}
+		if output = strings.TrimRight(output, "\n"); len(output) > 0 {
+			output += "\n"
+		}
+		return output, conv.Diagnostics(), nil
+	}
+
+	var sb strings.Builder
+	var diagnostics []Diagnostic
+	var section []string
+	flush := func() error {
+		sql := strings.Join(section, "\n")
+		section = section[:0]
+		if len(strings.TrimSpace(sql)) == 0 {
+			return nil
+		}
+		conv := NewDMDBWithOptions(sql, options)
+		output, err := conv.Exec()
+		if err != nil {
+			return err
+		}
+		diagnostics = append(diagnostics, conv.Diagnostics()...)
+		for _, stmt := range splitDMDBStatements(output) {
+			if dmdbBlockRegexp.MatchString(stmt) {
+				sb.WriteString(gooseDirectivePrefix + " StatementBegin\n" + stmt + "\n" + gooseDirectivePrefix + " StatementEnd\n")
+			} else {
+				sb.WriteString(stmt + ";\n")
+			}
+		}
+		return nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		directive := strings.TrimSpace(line)
+		if !strings.HasPrefix(directive, gooseDirectivePrefix) {
+			section = append(section, line)
+			continue
+		}
+		if err := flush(); err != nil {
+			return "", nil, err
+		}
+		// 原有的 StatementBegin、StatementEnd 按转换后的语句重新生成
+		switch strings.TrimSpace(strings.TrimPrefix(directive, gooseDirectivePrefix)) {
+		case "StatementBegin", "StatementEnd":
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(directive + "\n")
+		}
+	}
+	if err := flush(); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), diagnostics, nil
+}
+
+func isGooseMigration(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), gooseDirectivePrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertMigrationDir 转换 srcDir 中全部 .sql 迁移文件，按相同的相对路径及文件名写入 dstDir，
+// 版本号及执行顺序因此保持不变。其他文件（如 goose 的 Go 迁移）不会复制
+func ConvertMigrationDir(srcDir, dstDir string, options DMDBOptions) ([]*MigrationFile, error) {
+	var files []*MigrationFile
+	err := filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstDir, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".sql") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		output, diagnostics, err := ConvertMigration(string(content), options)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(target, []byte(output), 0644); err != nil {
+			return err
+		}
+		files = append(files, &MigrationFile{Path: rel, Diagnostics: diagnostics})
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,115 @@
+package convertor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertMigration_Goose(t *testing.T) {
+	content := "-- +goose Up\n" +
+		"-- +goose StatementBegin\n" +
+		"CREATE TABLE `draft` (`uuid` varchar(8) NOT NULL, `len` int GENERATED ALWAYS AS (char_length(`uuid`)) STORED, PRIMARY KEY (`uuid`));\n" +
+		"-- +goose StatementEnd\n" +
+		"\n" +
+		"-- +goose Down\n" +
+		"DROP TABLE `draft`;\n"
+
+	got, diagnostics, err := ConvertMigration(content, DMDBOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "-- +goose Up\n"+
+		"CREATE TABLE draft (\nuuid varchar2(8 CHAR) NOT NULL ,\nlen int );\n"+
+		"ALTER TABLE draft ADD CONSTRAINT pk_draft_uuid PRIMARY KEY (uuid);\n"+
+		"-- +goose StatementBegin\n"+
+		"CREATE OR REPLACE TRIGGER trg_draft_len\nBEFORE INSERT OR UPDATE ON draft\nFOR EACH ROW\nBEGIN\n   :NEW.len := char_length(:NEW.uuid);\nEND;\n"+
+		"-- +goose StatementEnd\n"+
+		"\n-- +goose Down\n"+
+		"DROP TABLE draft;\n", got)
+	assert.Len(t, diagnostics, 2)
+}
+
+func TestConvertMigration_Skipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		skipped []string
+	}{
+		{
+			name:    "alter and insert",
+			content: "ALTER TABLE `a` ADD COLUMN `b` int;\nINSERT INTO `a` VALUES (1);\n",
+			want:    "",
+			skipped: []string{
+				"[WARN] a: ALTER TABLE operation not converted: ADD COLUMN `b` int",
+				"[WARN] : statement not converted: INSERT INTO `a` VALUES (1)",
+			},
+		},
+		{
+			name: "goose up",
+			content: "-- +goose Up\n" +
+				"CREATE TABLE `a` (`id` int NOT NULL);\n" +
+				"CREATE INDEX `idx_id` ON `a` (`id`);\n" +
+				"INSERT INTO `a` VALUES (1);\n" +
+				"-- +goose Down\n" +
+				"DROP TABLE `a`;\n",
+			want: "-- +goose Up\n" +
+				"CREATE TABLE a (\nid int NOT NULL );\n" +
+				"\n-- +goose Down\n" +
+				"DROP TABLE a;\n",
+			skipped: []string{
+				"[WARN] a: statement not converted: CREATE INDEX `idx_id` ON `a` (`id`)",
+				"[WARN] : statement not converted: INSERT INTO `a` VALUES (1)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics, err := ConvertMigration(tt.content, DMDBOptions{})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+
+			file := &MigrationFile{Diagnostics: diagnostics}
+			var skipped []string
+			for _, d := range file.SkippedStatements() {
+				skipped = append(skipped, d.String())
+			}
+			assert.Equal(t, tt.skipped, skipped)
+		})
+	}
+}
+
+func TestConvertMigrationDir(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"000001_create_reply.up.sql":   "CREATE TABLE `reply` (`id` bigint NOT NULL, PRIMARY KEY (`id`));",
+		"000001_create_reply.down.sql": "DROP TABLE `reply`;",
+		"tenant/000002_noop.up.sql":    "",
+		"main.go":                      "package main",
+	}
+	for name, content := range files {
+		assert.Nil(t, os.MkdirAll(filepath.Join(srcDir, filepath.Dir(name)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644))
+	}
+
+	converted, err := ConvertMigrationDir(srcDir, dstDir, DMDBOptions{})
+	assert.Nil(t, err)
+	var paths []string
+	for _, f := range converted {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"000001_create_reply.down.sql", "000001_create_reply.up.sql", filepath.Join("tenant", "000002_noop.up.sql")}, paths)
+	for _, f := range converted {
+		assert.Empty(t, f.SkippedStatements())
+	}
+
+	down, err := os.ReadFile(filepath.Join(dstDir, "000001_create_reply.down.sql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "DROP TABLE reply;\n", string(down))
+	up, err := os.ReadFile(filepath.Join(dstDir, "000001_create_reply.up.sql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "CREATE TABLE reply (\nid bigint NOT NULL );\nALTER TABLE reply ADD CONSTRAINT pk_reply_id PRIMARY KEY (id);\n", string(up))
+	_, err = os.Stat(filepath.Join(dstDir, "main.go"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,473 @@
+package convertor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// sqlparser 只支持 mysql 语法的一个子集，这里在解析之前对语句做一次预处理：
+// 将 sqlparser 不支持的部分从语句中剥离出来单独解析，剩下的部分再交给 sqlparser
+
+const mysqlIdent = "(?:`[^`]+`|\\w+)"
+
+var (
+	createTableRegexp = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:` + mysqlIdent + `\.)?(` + mysqlIdent + `)`)
+	alterTableRegexp  = regexp.MustCompile(`(?is)^ALTER\s+(?:IGNORE\s+)?TABLE\s+(?:` + mysqlIdent + `\.)?(` + mysqlIdent + `)\s+(.*)$`)
+	fulltextRegexp    = regexp.MustCompile("(?is)^FULLTEXT\\s+(?:(?:KEY|INDEX)\\s+)?(`[^`]+`|\\w+)?\\s*\\((.*)\\)\\s*(?:WITH\\s+PARSER\\s+(\\w+))?$")
+	foreignKeyRegexp  = regexp.MustCompile(`(?is)^(?:CONSTRAINT(?:\s+(` + mysqlIdent + `))?\s+)?FOREIGN\s+KEY\s*(?:` + mysqlIdent + `)?\s*\(([^)]*)\)\s*` +
+		`REFERENCES\s+(?:` + mysqlIdent + `\.)?(` + mysqlIdent + `)\s*\(([^)]*)\)(.*)$`)
+	generatedColumnRegexp = regexp.MustCompile(`(?is)\b(?:GENERATED\s+ALWAYS\s+)?AS\s*\(`)
+	generatedStoreRegexp  = regexp.MustCompile(`(?is)^\s*(VIRTUAL|STORED|PERSISTENT)\b`)
+	identRegexp           = regexp.MustCompile(`^` + mysqlIdent + `$`)
+	dropForeignKeyRegexp  = regexp.MustCompile(`(?is)^DROP\s+FOREIGN\s+KEY\s+(` + mysqlIdent + `)$`)
+	sridRegexp            = regexp.MustCompile(`(?is)\s+SRID\s+(\d+)\b`)
+	typeSynonymRegexp     = regexp.MustCompile(`(?is)^(\s*` + mysqlIdent + `\s+)(BOOLEAN|BOOL|DEC|FIXED|INT[1-48]|MIDDLEINT|DOUBLE\s+PRECISION)\b`)
+	floatPrecisionRegexp  = regexp.MustCompile(`(?is)^(\s*` + mysqlIdent + `\s+)FLOAT\s*\(\s*(\d+)\s*\)`)
+	boolDefaultRegexp     = regexp.MustCompile(`(?is)\bDEFAULT\s+(TRUE|FALSE)\b`)
+	hexDefaultRegexp      = regexp.MustCompile(`(?is)\bDEFAULT\s+(?:X'([0-9a-f]*)'|0x([0-9a-f]+))`)
+	referenceOptionRegexp = regexp.MustCompile(`(?is)ON\s+(DELETE|UPDATE)\s+(RESTRICT|CASCADE|SET\s+NULL|SET\s+DEFAULT|NO\s+ACTION)`)
+)
+
+// mysqlStatement 预处理后的 mysql 语句
+type mysqlStatement struct {
+	sql             string                       // 交给 sqlparser 解析的语句
+	createTable     string                       // CREATE TABLE 的表名
+	indexes         []*sqlparser.IndexDefinition // 从 CREATE TABLE 中剥离的索引（如 FULLTEXT）
+	partition       *Partition                   // CREATE TABLE 中的 PARTITION BY
+	generated       map[string]*GeneratedColumn  // 生成列，key 为小写的列名
+	alterTable      string                       // ALTER TABLE 的表名，sqlparser 不解析 ALTER TABLE 的内容，由预处理完成
+	alterPartitions []*AlterPartition            // ALTER TABLE 中的分区操作
+	foreignKeys     []*ForeignKey                // CREATE TABLE 或 ALTER TABLE ADD 中的外键，未命名时 Name 为空
+	dropForeignKeys []string                     // ALTER TABLE DROP FOREIGN KEY 中的外键名
+	skippedSpecs    []string                     // ALTER TABLE 中不转换的操作
+	diagnostics     Diagnostics                  // 预处理时产生的提示
+	srids           map[string]int               // 空间类型列的 SRID，key 为小写的列名
+}
+
+// parseMysqlStatements 将 sql 拆分为单条语句并预处理
+func parseMysqlStatements(sql string) []*mysqlStatement {
+	var statements []*mysqlStatement
+	for _, raw := range splitSQL(sql, ';') {
+		// 只含注释的语句（如文件末尾的注释）
+		if len(strings.TrimSpace(skipSQLComments(raw))) == 0 {
+			continue
+		}
+		statements = append(statements, preprocessMysqlStatement(raw))
+	}
+	return statements
+}
+
+func preprocessMysqlStatement(raw string) *mysqlStatement {
+	stmt := &mysqlStatement{sql: raw}
+
+	body := skipSQLComments(raw)
+	if matches := alterTableRegexp.FindStringSubmatch(body); matches != nil {
+		stmt.alterTable = trimIdent(matches[1])
+		preprocessAlterTable(stmt, matches[2])
+		return stmt
+	}
+	matches := createTableRegexp.FindStringSubmatch(body)
+	if matches == nil {
+		return stmt
+	}
+	stmt.createTable = trimIdent(matches[1])
+	start := indexSQL(raw, '(')
+	if start < 0 {
+		return stmt
+	}
+	end := matchParen(raw, start)
+	if end < 0 {
+		return stmt
+	}
+
+	var items []string
+	for _, item := range splitSQL(raw[start+1:end], ',') {
+		trimmed := strings.TrimSpace(item)
+		if index, empty := parseFulltextIndex(trimmed); index != nil {
+			name := index.Info.Name.String()
+			switch {
+			case len(index.Columns) == 0:
+				stmt.diagnostics.Warnf(stmt.createTable, name, "fulltext index without columns skipped")
+				continue
+			case empty:
+				stmt.diagnostics.Warnf(stmt.createTable, name, "empty column names in fulltext index ignored")
+			}
+			stmt.indexes = append(stmt.indexes, index)
+			continue
+		}
+		if fk := parseForeignKey(trimmed); fk != nil {
+			stmt.foreignKeys = append(stmt.foreignKeys, fk)
+			continue
+		}
+		if name, generated, column := parseGeneratedColumn(trimmed); generated != nil {
+			if stmt.generated == nil {
+				stmt.generated = map[string]*GeneratedColumn{}
+			}
+			stmt.generated[strings.ToLower(name)] = generated
+			item = item[:strings.Index(item, trimmed)] + column
+		}
+		if srid, column := parseSRID(item); srid > 0 {
+			if stmt.srids == nil {
+				stmt.srids = map[string]int{}
+			}
+			stmt.srids[strings.ToLower(trimIdent(strings.Fields(trimmed)[0]))] = srid
+			item = column
+		}
+		items = append(items, rewriteBoolDefault(rewriteHexDefault(rewriteTypeSynonym(rewriteFloatPrecision(item)))))
+	}
+	tail := raw[end:]
+	if i, j := findPartitionClause(tail); i >= 0 {
+		stmt.partition = parsePartitionSpec(tail[i:j])
+		tail = tail[:i]
+	}
+	stmt.sql = raw[:start+1] + strings.Join(items, ",") + tail
+	return stmt
+}
+
+// preprocessAlterTable 解析 ALTER TABLE 中的外键及分区操作，其余操作记录在 skippedSpecs 中
+func preprocessAlterTable(stmt *mysqlStatement, specs string) {
+	var last *AlterPartition
+	for _, spec := range splitSQL(specs, ',') {
+		spec = strings.TrimSpace(spec)
+		// DROP/TRUNCATE PARTITION p1, p2 中的分区名同样以逗号分隔
+		if last != nil && len(last.Names) > 0 && identRegexp.MatchString(spec) {
+			last.Names = append(last.Names, trimIdent(spec))
+			continue
+		}
+		last = parseAlterPartition(spec)
+		if last != nil {
+			stmt.alterPartitions = append(stmt.alterPartitions, last)
+			continue
+		}
+		if matches := dropForeignKeyRegexp.FindStringSubmatch(spec); matches != nil {
+			stmt.dropForeignKeys = append(stmt.dropForeignKeys, trimIdent(matches[1]))
+			continue
+		}
+		if len(spec) >= 4 && strings.EqualFold(spec[:4], "ADD ") {
+			if fk := parseForeignKey(strings.TrimSpace(spec[4:])); fk != nil {
+				stmt.foreignKeys = append(stmt.foreignKeys, fk)
+				continue
+			}
+		}
+		stmt.skippedSpecs = append(stmt.skippedSpecs, spec)
+	}
+}
+
+// parseGeneratedColumn 解析生成列，返回列名、生成列的定义以及去掉生成表达式之后的列定义
+func parseGeneratedColumn(item string) (string, *GeneratedColumn, string) {
+	loc := findSQL(item, generatedColumnRegexp)
+	if loc == nil {
+		return "", nil, item
+	}
+	open := loc[1] - 1
+	end := matchParen(item, open)
+	if end < 0 {
+		return "", nil, item
+	}
+	generated := &GeneratedColumn{Expr: strings.TrimSpace(item[open+1 : end])}
+	rest := item[end+1:]
+	if m := generatedStoreRegexp.FindStringSubmatch(rest); m != nil {
+		generated.Stored = !strings.EqualFold(m[1], "VIRTUAL")
+		rest = rest[len(m[0]):]
+	}
+	return trimIdent(strings.Fields(item)[0]), generated, strings.TrimSpace(item[:loc[0]]) + rest
+}
+
+// parseSRID 解析空间类型列的 SRID 属性（sqlparser 不支持），返回 SRID 以及去掉该属性之后的列定义
+func parseSRID(item string) (int, string) {
+	loc := findSQL(item, sridRegexp)
+	if loc == nil {
+		return 0, item
+	}
+	srid, _ := strconv.Atoi(sridRegexp.FindStringSubmatch(item[loc[0]:loc[1]])[1])
+	return srid, item[:loc[0]] + item[loc[1]:]
+}
+
+// rewriteFloatPrecision sqlparser 不支持 float(p)，按 mysql 的规则改写，p 不大于 24 时为 float，否则为 double
+func rewriteFloatPrecision(item string) string {
+	matches := floatPrecisionRegexp.FindStringSubmatch(item)
+	if matches == nil {
+		return item
+	}
+	typeName := "float"
+	if p, _ := strconv.Atoi(matches[2]); p > 24 {
+		typeName = "double"
+	}
+	return matches[1] + typeName + item[len(matches[0]):]
+}
+
+// rewriteTypeSynonym sqlparser 不支持 bool、dec 等类型的同义词，改写为 mysql 实际使用的类型，bool 改写为 tinyint(1)
+func rewriteTypeSynonym(item string) string {
+	matches := typeSynonymRegexp.FindStringSubmatch(item)
+	if matches == nil {
+		return item
+	}
+	typeName := normalizeMysqlTypeName(strings.Join(strings.Fields(matches[2]), " "))
+	if typeName == "tinyint" && strings.HasPrefix(strings.ToLower(matches[2]), "bool") {
+		typeName = "tinyint(1)"
+	}
+	return matches[1] + typeName + item[len(matches[0]):]
+}
+
+// rewriteHexDefault sqlparser 不支持 DEFAULT x'..' 及 DEFAULT 0x..，改写为位数相同的 DEFAULT b'..'
+func rewriteHexDefault(item string) string {
+	loc := findSQL(item, hexDefaultRegexp)
+	if loc == nil {
+		return item
+	}
+	matches := hexDefaultRegexp.FindStringSubmatch(item[loc[0]:loc[1]])
+	hex := matches[1] + matches[2]
+	var bits strings.Builder
+	for _, c := range hex {
+		v, _ := strconv.ParseUint(string(c), 16, 8)
+		bits.WriteString(fmt.Sprintf("%04b", v))
+	}
+	return item[:loc[0]] + "DEFAULT b'" + bits.String() + "'" + item[loc[1]:]
+}
+
+// rewriteBoolDefault sqlparser 不支持 TRUE、FALSE 字面量，与 mysql 一致改写为 1、0
+func rewriteBoolDefault(item string) string {
+	loc := findSQL(item, boolDefaultRegexp)
+	if loc == nil {
+		return item
+	}
+	value := "0"
+	if strings.EqualFold(boolDefaultRegexp.FindStringSubmatch(item[loc[0]:loc[1]])[1], "true") {
+		value = "1"
+	}
+	return item[:loc[0]] + "DEFAULT " + value + item[loc[1]:]
+}
+
+// parseForeignKey 解析 [CONSTRAINT [name]] FOREIGN KEY [index_name] (col, ...) REFERENCES tbl (col, ...) [ON DELETE ...] [ON UPDATE ...]
+func parseForeignKey(item string) *ForeignKey {
+	matches := foreignKeyRegexp.FindStringSubmatch(item)
+	if matches == nil {
+		return nil
+	}
+	fk := &ForeignKey{
+		Name:       trimIdent(matches[1]),
+		Columns:    parseIdentList(matches[2]),
+		RefTable:   trimIdent(matches[3]),
+		RefColumns: parseIdentList(matches[4]),
+	}
+	for _, option := range referenceOptionRegexp.FindAllStringSubmatch(matches[5], -1) {
+		action := strings.ToUpper(strings.Join(strings.Fields(option[2]), " "))
+		if strings.EqualFold(option[1], "DELETE") {
+			fk.OnDelete = action
+		} else {
+			fk.OnUpdate = action
+		}
+	}
+	return fk
+}
+
+func parseIdentList(list string) []string {
+	var result []string
+	for _, ident := range splitSQL(list, ',') {
+		result = append(result, trimIdent(ident))
+	}
+	return result
+}
+
+// parseFulltextIndex 解析 FULLTEXT [KEY|INDEX] [name] (col, ...) [WITH PARSER parser]
+// 列名为空时 empty 为 true
+func parseFulltextIndex(item string) (index *sqlparser.IndexDefinition, empty bool) {
+	matches := fulltextRegexp.FindStringSubmatch(item)
+	if matches == nil {
+		return nil, false
+	}
+	columns, empty := parseIndexColumnNames(matches[2])
+	name := trimIdent(matches[1])
+	if len(name) == 0 && len(columns) > 0 {
+		// 与 mysql 一致，未命名的索引使用第一列的列名
+		name = columns[0].Column.String()
+	}
+	index = &sqlparser.IndexDefinition{
+		Info: &sqlparser.IndexInfo{
+			Type: "fulltext key",
+			Name: sqlparser.NewColIdent(name),
+		},
+		Columns: columns,
+	}
+	if len(matches[3]) > 0 {
+		index.Options = append(index.Options, &sqlparser.IndexOption{
+			Name:  "with parser",
+			Value: sqlparser.NewStrVal([]byte(strings.ToLower(matches[3]))),
+		})
+	}
+	return index, empty
+}
+
+// parseIndexColumnNames 解析索引的列名，跳过空的列名，有空的列名时 empty 为 true
+func parseIndexColumnNames(columns string) (result []*sqlparser.IndexColumn, empty bool) {
+	for _, col := range splitSQL(columns, ',') {
+		col = strings.TrimSpace(col)
+		// 去掉可能存在的前缀长度及排序
+		if i := indexSQL(col, '('); i >= 0 {
+			col = col[:i]
+		}
+		fields := strings.Fields(col)
+		if len(fields) == 0 || len(trimIdent(fields[0])) == 0 {
+			empty = true
+			continue
+		}
+		result = append(result, &sqlparser.IndexColumn{Column: sqlparser.NewColIdent(trimIdent(fields[0]))})
+	}
+	return result, empty
+}
+
+func trimIdent(ident string) string {
+	return strings.Trim(strings.TrimSpace(ident), "`\"")
+}
+
+// splitSQL 在最外层（引号、注释、括号之外）按 sep 拆分 sql
+func splitSQL(sql string, sep byte) []string {
+	var result []string
+	last := 0
+	scanSQL(sql, func(i, depth int) bool {
+		if depth == 0 && sql[i] == sep {
+			result = append(result, sql[last:i])
+			last = i + 1
+		}
+		return true
+	})
+	return append(result, sql[last:])
+}
+
+// indexSQL 返回引号、注释之外第一个 c 的位置
+func indexSQL(sql string, c byte) int {
+	found := -1
+	scanSQL(sql, func(i, depth int) bool {
+		if sql[i] == c {
+			found = i
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// findSQL 返回 re 在引号、注释及括号之外的第一个匹配的位置
+func findSQL(sql string, re *regexp.Regexp) []int {
+	for _, loc := range re.FindAllStringIndex(sql, -1) {
+		if depth, ok := sqlDepth(sql, loc[0]); ok && depth == 0 {
+			return loc
+		}
+	}
+	return nil
+}
+
+// inSQLQuote 判断 sql[pos] 是否位于引号或注释中
+func inSQLQuote(sql string, pos int) bool {
+	_, ok := sqlDepth(sql, pos)
+	return !ok
+}
+
+// sqlDepth 返回 sql[pos] 所在的括号深度，位于引号或注释中时返回 false
+func sqlDepth(sql string, pos int) (int, bool) {
+	result, found := 0, false
+	scanSQL(sql[:pos+1], func(i, depth int) bool {
+		if i == pos {
+			result, found = depth, true
+			return false
+		}
+		return true
+	})
+	return result, found
+}
+
+// matchParen 返回与 sql[open] 处的左括号对应的右括号的位置
+func matchParen(sql string, open int) int {
+	found := -1
+	scanSQL(sql[open:], func(i, depth int) bool {
+		if depth == 0 && sql[open+i] == ')' {
+			found = open + i
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// skipSQLComments 去掉 sql 开头的空白及注释
+func skipSQLComments(sql string) string {
+	for {
+		sql = strings.TrimSpace(sql)
+		switch {
+		case strings.HasPrefix(sql, "--"), strings.HasPrefix(sql, "#"):
+			i := strings.IndexByte(sql, '\n')
+			if i < 0 {
+				return ""
+			}
+			sql = sql[i+1:]
+		case strings.HasPrefix(sql, "/*") && !strings.HasPrefix(sql, "/*!"):
+			i := strings.Index(sql, "*/")
+			if i < 0 {
+				return ""
+			}
+			sql = sql[i+2:]
+		default:
+			return sql
+		}
+	}
+}
+
+// scanSQL 依次访问引号、注释之外的字符，depth 为该字符所在的括号深度
+// （左括号的 depth 为其外层深度，右括号同理），fn 返回 false 时停止
+func scanSQL(sql string, fn func(i, depth int) bool) {
+	depth := 0
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+			continue
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "-- ")):
+			if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(sql)
+			}
+			continue
+		case c == '/' && strings.HasPrefix(sql[i:], "/*") && !strings.HasPrefix(sql[i:], "/*!"):
+			if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(sql)
+			}
+			continue
+		case c == ')':
+			depth--
+		}
+		if !fn(i, depth) {
+			return
+		}
+		if c == '(' {
+			depth++
+		}
+	}
+}
+
+// skipQuoted 返回 sql[start] 处开始的引号字符串结束的位置
+func skipQuoted(sql string, start int) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
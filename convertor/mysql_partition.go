@@ -0,0 +1,155 @@
+package convertor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	partitionClauseRegexp = regexp.MustCompile(`(?is)(/\*!\d*\s*)?PARTITION\s+BY\s`)
+	partitionByRegexp     = regexp.MustCompile(`(?is)^PARTITION\s+BY\s+(LINEAR\s+)?(RANGE|LIST|HASH|KEY)(\s+COLUMNS)?\s*(?:ALGORITHM\s*=\s*\d+\s*)?\(`)
+	partitionsRegexp      = regexp.MustCompile(`(?is)^PARTITIONS\s+(\d+)`)
+	partitionDefRegexp    = regexp.MustCompile(`(?is)^PARTITION\s+(` + mysqlIdent + `)(?:\s+VALUES\s+(LESS\s+THAN|IN))?\s*(.*)$`)
+	alterPartitionRegexp  = regexp.MustCompile(`(?is)^(ADD|DROP|TRUNCATE)\s+PARTITION\s*(.*)$`)
+	identListRegexp       = regexp.MustCompile(`^` + mysqlIdent + `(?:\s*,\s*` + mysqlIdent + `)*$`)
+)
+
+// findPartitionClause 返回 CREATE TABLE 结尾部分中 PARTITION BY 子句的位置，
+// mysqldump 会将其包在 /*!50100 ... */ 中，返回的范围包含注释标记
+func findPartitionClause(tail string) (int, int) {
+	var loc []int
+	for _, l := range partitionClauseRegexp.FindAllStringIndex(tail, -1) {
+		// 忽略 COMMENT 等字符串中的内容
+		if !inSQLQuote(tail, l[0]) {
+			loc = l
+			break
+		}
+	}
+	if loc == nil {
+		return -1, -1
+	}
+	end := len(tail)
+	if strings.HasPrefix(tail[loc[0]:], "/*!") {
+		if i := strings.LastIndex(tail, "*/"); i > loc[0] {
+			end = i + 2
+		}
+	}
+	return loc[0], end
+}
+
+func parsePartitionSpec(clause string) *Partition {
+	clause = strings.TrimSpace(clause)
+	if strings.HasPrefix(clause, "/*!") {
+		clause = strings.TrimSuffix(clause, "*/")
+		clause = strings.TrimLeft(clause[3:], "0123456789")
+		clause = strings.TrimSpace(clause)
+	}
+
+	matches := partitionByRegexp.FindStringSubmatchIndex(clause)
+	if matches == nil {
+		return nil
+	}
+	spec := &Partition{
+		Method:  strings.ToUpper(clause[matches[4]:matches[5]]),
+		Linear:  matches[2] >= 0,
+		Columns: matches[6] >= 0,
+	}
+	open := matches[1] - 1
+	end := matchParen(clause, open)
+	if end < 0 {
+		return nil
+	}
+	spec.Expr = strings.TrimSpace(clause[open+1 : end])
+
+	rest := strings.TrimSpace(clause[end+1:])
+	if m := partitionsRegexp.FindStringSubmatch(rest); m != nil {
+		spec.Partitions, _ = strconv.Atoi(m[1])
+		rest = strings.TrimSpace(rest[len(m[0]):])
+	}
+	if i := indexSQL(rest, '('); i >= 0 {
+		if strings.Contains(strings.ToUpper(rest[:i]), "SUBPARTITION") {
+			spec.Subpartition = true
+		}
+		if j := matchParen(rest, i); j > 0 {
+			spec.Definitions = parsePartitionDefinitions(rest[i+1 : j])
+		}
+	} else if strings.Contains(strings.ToUpper(rest), "SUBPARTITION") {
+		spec.Subpartition = true
+	}
+	for _, def := range spec.Definitions {
+		if def == nil {
+			// 分区定义无法解析（例如包含子分区）
+			spec.Subpartition = true
+		}
+	}
+	return spec
+}
+
+func parsePartitionDefinitions(defs string) []*PartitionDefinition {
+	var result []*PartitionDefinition
+	for _, def := range splitSQL(defs, ',') {
+		result = append(result, parsePartitionDefinition(strings.TrimSpace(def)))
+	}
+	return result
+}
+
+// parsePartitionDefinition 解析 PARTITION name [VALUES {LESS THAN {(expr) | MAXVALUE} | IN (values)}] [options]
+// 分区的 ENGINE、COMMENT 等选项会被忽略，包含子分区时返回 nil
+func parsePartitionDefinition(def string) *PartitionDefinition {
+	matches := partitionDefRegexp.FindStringSubmatch(def)
+	if matches == nil {
+		return nil
+	}
+	result := &PartitionDefinition{Name: trimIdent(matches[1])}
+	rest := strings.TrimSpace(matches[3])
+	if len(matches[2]) > 0 {
+		result.LessThan = strings.HasPrefix(strings.ToUpper(matches[2]), "LESS")
+		if strings.HasPrefix(rest, "(") {
+			end := matchParen(rest, 0)
+			if end < 0 {
+				return nil
+			}
+			result.Values = strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+		} else if len(rest) >= 8 && strings.EqualFold(rest[:8], "MAXVALUE") {
+			result.Values = "MAXVALUE"
+			rest = rest[8:]
+		}
+		if strings.EqualFold(result.Values, "MAXVALUE") {
+			result.MaxValue = true
+			result.Values = ""
+		}
+	}
+	if indexSQL(rest, '(') >= 0 {
+		return nil
+	}
+	return result
+}
+
+// parseAlterPartition 解析 ADD PARTITION (...)、ADD PARTITION PARTITIONS n、DROP PARTITION p1、TRUNCATE PARTITION p1|ALL
+func parseAlterPartition(spec string) *AlterPartition {
+	matches := alterPartitionRegexp.FindStringSubmatch(spec)
+	if matches == nil {
+		return nil
+	}
+	result := &AlterPartition{Action: strings.ToUpper(matches[1])}
+	rest := strings.TrimSpace(matches[2])
+	switch result.Action {
+	case "ADD":
+		if m := partitionsRegexp.FindStringSubmatch(rest); m != nil {
+			result.Partitions, _ = strconv.Atoi(m[1])
+		} else if strings.HasPrefix(rest, "(") {
+			if end := matchParen(rest, 0); end > 0 {
+				result.Definitions = parsePartitionDefinitions(rest[1:end])
+			}
+		}
+	default:
+		if strings.EqualFold(rest, "ALL") {
+			result.All = true
+		} else if len(rest) > 0 {
+			result.Names = append(result.Names, trimIdent(rest))
+		}
+	}
+	return result
+}
@@ -0,0 +1,246 @@
+package convertor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// ParseMysqlSchema 解析 mysql DDL，返回与目标数据库无关的结构以及解析过程中产生的提示
+func ParseMysqlSchema(sql string) (*Schema, []Diagnostic) {
+	var diagnostics Diagnostics
+	schema := parseMysqlSchema(sql, &diagnostics)
+	return schema, diagnostics.List()
+}
+
+// ParseMysqlColumnType 解析 varchar(64)、decimal(10,2) unsigned 等 mysql 列类型
+func ParseMysqlColumnType(s string) (ColumnType, error) {
+	schema := parseMysqlSchema(fmt.Sprintf("CREATE TABLE t (c %s)", s), &Diagnostics{})
+	tables := schema.Tables()
+	if len(tables) != 1 || len(tables[0].Columns) != 1 {
+		return ColumnType{}, fmt.Errorf("invalid mysql column type '%s'", s)
+	}
+	return tables[0].Columns[0].Type, nil
+}
+
+func parseMysqlSchema(sql string, diagnostics *Diagnostics) *Schema {
+	var schema = &Schema{}
+	// 每个表已有的外键数量，用于给未命名的外键命名
+	var foreignKeyCount = map[string]int{}
+
+	for _, stmt := range parseMysqlStatements(sql) {
+		diagnostics.list = append(diagnostics.list, stmt.diagnostics.List()...)
+		if len(stmt.alterTable) > 0 {
+			schema.Statements = append(schema.Statements, &Statement{
+				Kind: StatementAlterTable,
+				Alter: &AlterTable{
+					Name:            stmt.alterTable,
+					AddForeignKeys:  nameForeignKeys(foreignKeyCount, stmt.alterTable, stmt.foreignKeys),
+					DropForeignKeys: stmt.dropForeignKeys,
+					Partitions:      stmt.alterPartitions,
+				},
+			})
+			for _, spec := range stmt.skippedSpecs {
+				diagnostics.Skipf(stmt.alterTable, "ALTER TABLE operation not converted: %s", firstLine(spec))
+			}
+			continue
+		}
+
+		st, err := sqlparser.ParseNext(sqlparser.NewStringTokenizer(stmt.sql))
+		if err != nil {
+			diagnostics.Skipf(stmt.createTable, "statement not supported by the parser skipped: %v", err)
+			if len(stmt.createTable) > 0 {
+				schema.SkippedTables = append(schema.SkippedTables, stmt.createTable)
+			}
+			continue
+		}
+
+		// TODO view table
+		ddl, ok := st.(*sqlparser.DDL)
+		if !ok {
+			diagnostics.Skipf("", "statement not converted: %s", firstLine(strings.TrimSpace(skipSQLComments(stmt.sql))))
+			continue
+		}
+		switch ddl.Action {
+		case sqlparser.DropStr:
+			schema.Statements = append(schema.Statements, &Statement{
+				Kind: StatementDropTable,
+				Drop: &DropTable{Name: ddl.Table.Name.String(), IfExists: ddl.IfExists},
+			})
+		case sqlparser.CreateStr:
+			if ddl.TableSpec == nil {
+				diagnostics.Skipf(ddl.NewName.Name.String(), "unsupported CREATE statement skipped")
+				schema.SkippedTables = append(schema.SkippedTables, ddl.NewName.Name.String())
+				continue
+			}
+			table := buildMysqlTable(ddl, stmt)
+			table.ForeignKeys = nameForeignKeys(foreignKeyCount, table.Name, stmt.foreignKeys)
+			schema.Statements = append(schema.Statements, &Statement{Kind: StatementCreateTable, Table: table})
+		default:
+			diagnostics.Skipf(ddl.Table.Name.String(), "statement not converted: %s", firstLine(strings.TrimSpace(skipSQLComments(stmt.sql))))
+		}
+	}
+	return schema
+}
+
+// nameForeignKeys 填充外键所在的表，与 mysql 一致，未命名的外键按 <table>_ibfk_<n> 命名，
+// n 为该表已有的 <table>_ibfk_<n> 形式的外键名中最大的序号加 1，其他命名的外键不占用序号
+func nameForeignKeys(count map[string]int, tableName string, foreignKeys []*ForeignKey) []*ForeignKey {
+	prefix := strings.ToLower(tableName) + "_ibfk_"
+	for _, fk := range foreignKeys {
+		fk.Table = tableName
+		name := strings.ToLower(fk.Name)
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(name[len(prefix):]); err == nil && n > count[tableName] {
+			count[tableName] = n
+		}
+	}
+	for _, fk := range foreignKeys {
+		if len(fk.Name) == 0 {
+			count[tableName]++
+			fk.Name = fmt.Sprintf("%s_ibfk_%d", tableName, count[tableName])
+		}
+	}
+	return foreignKeys
+}
+
+func buildMysqlTable(ddl *sqlparser.DDL, stmt *mysqlStatement) *Table {
+	options := parseMysqlTableOptions(ddl.TableSpec.Options)
+	table := &Table{
+		Name:      ddl.NewName.Name.String(),
+		Options:   options,
+		Partition: stmt.partition,
+	}
+	table.Comment, _ = options.Get("comment")
+
+	for _, def := range ddl.TableSpec.Columns {
+		column := buildMysqlColumn(def)
+		column.Generated = stmt.generated[def.Name.Lowered()]
+		column.SRID = stmt.srids[def.Name.Lowered()]
+		table.Columns = append(table.Columns, column)
+	}
+	for _, def := range append(ddl.TableSpec.Indexes, stmt.indexes...) {
+		table.Indexes = append(table.Indexes, buildMysqlIndex(def))
+	}
+	return table
+}
+
+// mysqlTypeSynonyms mysql 类型的同义词，解析时统一为 mysql 实际使用的类型，bool、boolean 为 tinyint(1)
+var mysqlTypeSynonyms = map[string]string{
+	"integer":          "int",
+	"int1":             "tinyint",
+	"int2":             "smallint",
+	"int3":             "mediumint",
+	"int4":             "int",
+	"int8":             "bigint",
+	"middleint":        "mediumint",
+	"dec":              "decimal",
+	"numeric":          "decimal",
+	"fixed":            "decimal",
+	"real":             "double", // 未开启 REAL_AS_FLOAT 时
+	"double precision": "double",
+	"bool":             "tinyint",
+	"boolean":          "tinyint",
+}
+
+// normalizeMysqlTypeName 返回类型名对应的标准类型名
+func normalizeMysqlTypeName(name string) string {
+	name = strings.ToLower(name)
+	if synonym, found := mysqlTypeSynonyms[name]; found {
+		return synonym
+	}
+	return name
+}
+
+func buildMysqlColumn(def *sqlparser.ColumnDefinition) *Column {
+	ct := def.Type
+	column := &Column{
+		Name: def.Name.String(),
+		Type: ColumnType{
+			Name:     normalizeMysqlTypeName(ct.Type),
+			Length:   sqlValInt(ct.Length),
+			Scale:    sqlValInt(ct.Scale),
+			Unsigned: bool(ct.Unsigned),
+			Zerofill: bool(ct.Zerofill),
+			Charset:  strings.ToLower(ct.Charset),
+			Collate:  strings.ToLower(ct.Collate),
+		},
+		NotNull:       bool(ct.NotNull),
+		AutoIncrement: bool(ct.Autoincrement),
+		Default:       buildMysqlDefault(ct.Default),
+	}
+	if name := strings.ToLower(ct.Type); name == "bool" || name == "boolean" {
+		column.Type.Length = intPtr(1)
+	}
+	for _, v := range ct.EnumValues {
+		column.Type.Values = append(column.Type.Values, strings.TrimSuffix(strings.TrimPrefix(v, "'"), "'"))
+	}
+	if ct.OnUpdate != nil {
+		column.OnUpdate = strings.ToUpper(string(ct.OnUpdate.Val))
+	}
+	if ct.Comment != nil {
+		column.Comment = string(ct.Comment.Val)
+	}
+	return column
+}
+
+func buildMysqlDefault(val *sqlparser.SQLVal) *DefaultValue {
+	if val == nil {
+		return nil
+	}
+	switch val.Type {
+	case sqlparser.ValArg:
+		// NULL、CURRENT_TIMESTAMP
+		if strings.EqualFold(string(val.Val), "null") {
+			return &DefaultValue{Kind: DefaultNull}
+		}
+		return &DefaultValue{Kind: DefaultCurrentTimestamp, Value: strings.ToUpper(string(val.Val))}
+	case sqlparser.IntVal, sqlparser.FloatVal:
+		return &DefaultValue{Kind: DefaultNumber, Value: string(val.Val)}
+	case sqlparser.BitVal:
+		return &DefaultValue{Kind: DefaultBits, Value: string(val.Val)}
+	default:
+		return &DefaultValue{Kind: DefaultString, Value: string(val.Val)}
+	}
+}
+
+func buildMysqlIndex(def *sqlparser.IndexDefinition) *Index {
+	info := def.Info
+	index := &Index{Name: info.Name.String()}
+	switch {
+	case info.Primary:
+		index.Kind = IndexPrimary
+	case isFulltextIndex(info):
+		index.Kind = IndexFulltext
+	case info.Spatial:
+		index.Kind = IndexSpatial
+	case info.Unique:
+		index.Kind = IndexUnique
+	default:
+		index.Kind = IndexNormal
+	}
+	for _, col := range def.Columns {
+		index.Columns = append(index.Columns, &IndexColumn{Name: col.Column.String(), Length: sqlValInt(col.Length)})
+	}
+	for _, opt := range def.Options {
+		if opt.Name == "with parser" && opt.Value != nil {
+			index.Parser = string(opt.Value.Val)
+		}
+	}
+	return index
+}
+
+func sqlValInt(val *sqlparser.SQLVal) *int {
+	if val == nil {
+		return nil
+	}
+	n, err := strconv.Atoi(string(val.Val))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,134 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMysqlSchema(t *testing.T) {
+	sql := "DROP TABLE IF EXISTS `reply`;\n" +
+		"CREATE TABLE `reply` (\n" +
+		"`uuid` varchar(8) NOT NULL,\n" +
+		"`title` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT 'untitled' COMMENT '标题',\n" +
+		"`kind` enum('a','b') DEFAULT NULL,\n" +
+		"`price` decimal(10,2) unsigned NOT NULL DEFAULT '0.00',\n" +
+		"`updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"`title_len` int GENERATED ALWAYS AS (char_length(`title`)) STORED,\n" +
+		"`draft_uuid` varchar(8) NOT NULL,\n" +
+		"PRIMARY KEY (`uuid`),\n" +
+		"UNIQUE KEY `title` (`title`(16)),\n" +
+		"FULLTEXT KEY `ft_title` (`title`) WITH PARSER ngram,\n" +
+		"FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`) ON DELETE CASCADE\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='回复';\n" +
+		"ALTER TABLE `reply` DROP FOREIGN KEY `reply_ibfk_1`;"
+
+	schema, diagnostics := ParseMysqlSchema(sql)
+	assert.Equal(t, 0, len(diagnostics))
+	assert.Equal(t, 3, len(schema.Statements))
+	assert.Equal(t, &DropTable{Name: "reply", IfExists: true}, schema.Statements[0].Drop)
+	assert.Equal(t, []string{"reply_ibfk_1"}, schema.Statements[2].Alter.DropForeignKeys)
+
+	tables := schema.Tables()
+	assert.Equal(t, 1, len(tables))
+	table := tables[0]
+	assert.Equal(t, "reply", table.Name)
+	assert.Equal(t, "回复", table.Comment)
+	assert.Equal(t, "utf8mb4", table.Options.Charset())
+
+	title := table.Column("TITLE")
+	assert.Equal(t, "varchar", title.Type.Name)
+	assert.Equal(t, 64, title.Type.LengthOr(0))
+	assert.Equal(t, "utf8mb4_bin", title.Type.Collate)
+	assert.Equal(t, &DefaultValue{Kind: DefaultString, Value: "untitled"}, title.Default)
+	assert.Equal(t, "标题", title.Comment)
+
+	assert.Equal(t, []string{"a", "b"}, table.Column("kind").Type.Values)
+	assert.Equal(t, &DefaultValue{Kind: DefaultNull}, table.Column("kind").Default)
+
+	price := table.Column("price")
+	assert.True(t, price.NotNull)
+	assert.True(t, price.Type.Unsigned)
+	assert.Equal(t, 2, *price.Type.Scale)
+
+	updatedAt := table.Column("updated_at")
+	assert.Equal(t, &DefaultValue{Kind: DefaultCurrentTimestamp, Value: "CURRENT_TIMESTAMP"}, updatedAt.Default)
+	assert.Equal(t, "CURRENT_TIMESTAMP", updatedAt.OnUpdate)
+
+	assert.Equal(t, &GeneratedColumn{Expr: "char_length(`title`)", Stored: true}, table.Column("title_len").Generated)
+
+	assert.Equal(t, []string{"uuid"}, table.PrimaryKey().ColumnNames())
+	assert.Equal(t, IndexUnique, table.Indexes[1].Kind)
+	assert.Equal(t, 16, *table.Indexes[1].Columns[0].Length)
+	assert.Equal(t, IndexFulltext, table.Indexes[2].Kind)
+	assert.Equal(t, "ngram", table.Indexes[2].Parser)
+
+	assert.Equal(t, []*ForeignKey{{
+		Name:       "reply_ibfk_1",
+		Table:      "reply",
+		Columns:    []string{"draft_uuid"},
+		RefTable:   "draft",
+		RefColumns: []string{"uuid"},
+		OnDelete:   "CASCADE",
+	}}, table.ForeignKeys)
+}
+
+func TestParseMysqlSchema_Unparsable(t *testing.T) {
+	sql := "CREATE TABLE `a` (`id` int NOT NULL);\n" +
+		"CREATE TABLE IF NOT EXISTS `b` (`x` int, CONSTRAINT c CHECK (x > 0));\n" +
+		"LOCK TABLES `a` WRITE;"
+
+	schema, diagnostics := ParseMysqlSchema(sql)
+	assert.Equal(t, 1, len(schema.Tables()))
+	assert.Equal(t, 2, len(diagnostics))
+	assert.Equal(t, "[WARN] b: unsupported CREATE statement skipped", diagnostics[0].String())
+	assert.Equal(t, DiagnosticWarning, diagnostics[1].Level)
+	assert.Contains(t, diagnostics[1].Message, "statement not supported by the parser skipped")
+}
+
+func TestParseMysqlSchema_ForeignKeyNames(t *testing.T) {
+	sql := "CREATE TABLE `reply` (`a` int, `b` int, `c` int,\n" +
+		"CONSTRAINT `reply_draft` FOREIGN KEY (`a`) REFERENCES `draft` (`id`),\n" +
+		"FOREIGN KEY (`b`) REFERENCES `user` (`id`));\n" +
+		"ALTER TABLE `reply` ADD CONSTRAINT `reply_ibfk_5` FOREIGN KEY (`c`) REFERENCES `team` (`id`);\n" +
+		"ALTER TABLE `reply` ADD FOREIGN KEY (`a`) REFERENCES `task` (`id`);"
+
+	schema, _ := ParseMysqlSchema(sql)
+	assert.Equal(t, "reply_draft", schema.Statements[0].Table.ForeignKeys[0].Name)
+	assert.Equal(t, "reply_ibfk_1", schema.Statements[0].Table.ForeignKeys[1].Name)
+	assert.Equal(t, "reply_ibfk_5", schema.Statements[1].Alter.AddForeignKeys[0].Name)
+	assert.Equal(t, "reply_ibfk_6", schema.Statements[2].Alter.AddForeignKeys[0].Name)
+}
+
+func TestParseMysqlSchema_TypeSynonyms(t *testing.T) {
+	schema, _ := ParseMysqlSchema("CREATE TABLE `t` (`a` integer, `b` dec(10,2), `c` numeric(5), `d` bool, `e` real, `f` int8, `g` double precision);")
+	var types []string
+	for _, column := range schema.Tables()[0].Columns {
+		types = append(types, column.Type.Name)
+	}
+	assert.Equal(t, []string{"int", "decimal", "decimal", "tinyint", "double", "bigint", "double"}, types)
+	assert.Equal(t, 1, *schema.Tables()[0].Columns[3].Type.Length)
+
+	diff := DiffSchema(schema, parseMysqlSchema("CREATE TABLE `t` (`a` int, `b` decimal(10,2), `c` decimal(5), `d` tinyint(1), `e` double, `f` bigint, `g` double);", &Diagnostics{}))
+	assert.Empty(t, diff.AlterTables)
+}
+
+func TestParseMysqlSchema_EmptyFulltextColumns(t *testing.T) {
+	sql := "CREATE TABLE `a` (`id` int NOT NULL, `title` varchar(64), `body` text,\n" +
+		"FULLTEXT KEY `ft_none` ( ),\n" +
+		"FULLTEXT KEY `ft_some` (`title`, , `body`));"
+
+	schema, diagnostics := ParseMysqlSchema(sql)
+	table := schema.Tables()[0]
+	assert.Len(t, table.Indexes, 1)
+	assert.Equal(t, []string{"title", "body"}, table.Indexes[0].ColumnNames())
+
+	var messages []string
+	for _, d := range diagnostics {
+		messages = append(messages, d.String())
+	}
+	assert.Equal(t, []string{
+		"[WARN] a.ft_none: fulltext index without columns skipped",
+		"[WARN] a.ft_some: empty column names in fulltext index ignored",
+	}, messages)
+}
@@ -0,0 +1,83 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitSQL(t *testing.T) {
+	sql := "DROP TABLE IF EXISTS `a;b`;\n" +
+		"-- comment; with semicolon\n" +
+		"CREATE TABLE t (`c` varchar(8) DEFAULT 'x;y' COMMENT 'it''s; \\'ok', KEY k (`c`(4)));\n" +
+		"/* block; comment */"
+
+	result := splitSQL(sql, ';')
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, "DROP TABLE IF EXISTS `a;b`", result[0])
+
+	items := splitSQL("`c` varchar(8) DEFAULT 'x,y', KEY k (`a`, `b`(4))", ',')
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, " KEY k (`a`, `b`(4))", items[1])
+}
+
+func Test_preprocessMysqlStatement(t *testing.T) {
+	stmt := preprocessMysqlStatement("\n-- Table structure\nCREATE TABLE `t` (\n" +
+		"`id` int,\n`body` text,\nFULLTEXT KEY `ft_body` (`body`) WITH PARSER ngram\n) ENGINE=InnoDB")
+	assert.Equal(t, "\n-- Table structure\nCREATE TABLE `t` (\n`id` int,\n`body` text) ENGINE=InnoDB", stmt.sql)
+	assert.Equal(t, 1, len(stmt.indexes))
+	assert.Equal(t, "ft_body", stmt.indexes[0].Info.Name.String())
+	assert.Equal(t, "body", stmt.indexes[0].Columns[0].Column.String())
+	assert.Equal(t, "ngram", string(stmt.indexes[0].Options[0].Value.Val))
+
+	stmt = preprocessMysqlStatement("DROP TABLE `t`")
+	assert.Equal(t, "DROP TABLE `t`", stmt.sql)
+	assert.Equal(t, 0, len(stmt.indexes))
+}
+
+func Test_parsePartitionSpec(t *testing.T) {
+	spec := parsePartitionSpec("/*!50500 PARTITION BY RANGE  COLUMNS(`day`, `id`)\n" +
+		"(PARTITION p0 VALUES LESS THAN ('2022-01-01', 10) COMMENT = 'p,0' ENGINE = InnoDB,\n" +
+		" PARTITION p1 VALUES LESS THAN (MAXVALUE, MAXVALUE) ENGINE = InnoDB) */")
+	assert.Equal(t, "RANGE", spec.Method)
+	assert.True(t, spec.Columns)
+	assert.Equal(t, "`day`, `id`", spec.Expr)
+	assert.False(t, spec.Subpartition)
+	assert.Equal(t, 2, len(spec.Definitions))
+	assert.Equal(t, "'2022-01-01', 10", spec.Definitions[0].Values)
+	assert.Equal(t, "MAXVALUE, MAXVALUE", spec.Definitions[1].Values)
+
+	spec = parsePartitionSpec("PARTITION BY RANGE (id) SUBPARTITION BY HASH (id) SUBPARTITIONS 2 (PARTITION p0 VALUES LESS THAN (10))")
+	assert.True(t, spec.Subpartition)
+
+	spec = parsePartitionSpec("PARTITION BY HASH (`id`) PARTITIONS 8")
+	assert.Equal(t, "HASH", spec.Method)
+	assert.Equal(t, 8, spec.Partitions)
+	assert.Equal(t, 0, len(spec.Definitions))
+
+	i, j := findPartitionClause(") ENGINE=InnoDB COMMENT='partition by day'")
+	assert.Equal(t, -1, i)
+	assert.Equal(t, -1, j)
+}
+
+func Test_rewriteHexDefault(t *testing.T) {
+	assert.Equal(t, "`k` binary(2) DEFAULT b'0000000010101011' NOT NULL", rewriteHexDefault("`k` binary(2) DEFAULT x'00aB' NOT NULL"))
+	assert.Equal(t, "`k` varbinary(4) DEFAULT b'00011111'", rewriteHexDefault("`k` varbinary(4) DEFAULT 0x1F"))
+	assert.Equal(t, "`k` int DEFAULT '1' COMMENT 'DEFAULT 0x1F'", rewriteHexDefault("`k` int DEFAULT '1' COMMENT 'DEFAULT 0x1F'"))
+}
+
+func Test_parseSRID(t *testing.T) {
+	srid, column := parseSRID("`geo` point NOT NULL SRID 4326 COMMENT 'SRID 0'")
+	assert.Equal(t, 4326, srid)
+	assert.Equal(t, "`geo` point NOT NULL COMMENT 'SRID 0'", column)
+
+	srid, column = parseSRID("`geo` point NOT NULL")
+	assert.Equal(t, 0, srid)
+	assert.Equal(t, "`geo` point NOT NULL", column)
+}
+
+func Test_rewriteFloatPrecision(t *testing.T) {
+	assert.Equal(t, "`ratio` float DEFAULT NULL", rewriteFloatPrecision("`ratio` float(24) DEFAULT NULL"))
+	assert.Equal(t, "\n  `ratio` double NOT NULL", rewriteFloatPrecision("\n  `ratio` FLOAT(25) NOT NULL"))
+	assert.Equal(t, "`ratio` float(7,2) NOT NULL", rewriteFloatPrecision("`ratio` float(7,2) NOT NULL"))
+}
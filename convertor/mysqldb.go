@@ -0,0 +1,304 @@
+package convertor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var _ Element = (*mysqldbCreateTable)(nil)
+var _ Element = (*mysqldbDropTable)(nil)
+var _ Element = (*mysqldbAlterTable)(nil)
+
+// MysqlDB 将 myto 输出的达梦 DDL 转换回 mysql DDL，用于回滚
+type MysqlDB struct {
+	sql         string
+	diagnostics Diagnostics
+}
+
+func NewMysqlDB(sql string) *MysqlDB {
+	return &MysqlDB{sql: sql}
+}
+
+// Diagnostics 返回最近一次转换产生的提示
+func (o *MysqlDB) Diagnostics() []Diagnostic {
+	return o.diagnostics.List()
+}
+
+func (o *MysqlDB) Exec() (string, error) {
+	o.diagnostics = Diagnostics{}
+	schema := parseDMDBSchema(o.sql, &o.diagnostics)
+	return renderMysqlSchema(schema), nil
+}
+
+// renderMysqlSchema 按语句的顺序输出 mysql DDL
+func renderMysqlSchema(schema *Schema) string {
+	var container = NewContainerWithSuffix("\n\n", true)
+	for _, stmt := range schema.Statements {
+		switch stmt.Kind {
+		case StatementDropTable:
+			container.Append(&mysqldbDropTable{DropTable: stmt.Drop})
+		case StatementCreateTable:
+			container.Append(&mysqldbCreateTable{Table: stmt.Table})
+		case StatementAlterTable:
+			container.Append(&mysqldbAlterTable{AlterTable: stmt.Alter})
+		}
+	}
+	return container.Render()
+}
+
+type mysqldbDropTable struct {
+	*DropTable
+}
+
+func (d *mysqldbDropTable) Format() string {
+	if d.IfExists {
+		return fmt.Sprintf("DROP TABLE IF EXISTS %s;", quoteMysqlIdent(d.Name))
+	}
+	return fmt.Sprintf("DROP TABLE %s;", quoteMysqlIdent(d.Name))
+}
+
+type mysqldbCreateTable struct {
+	*Table
+}
+
+func (o *mysqldbCreateTable) Format() string {
+	var lines []string
+	for _, column := range o.Columns {
+		lines = append(lines, "  "+formatMysqlColumn(column))
+	}
+	for _, index := range o.Indexes {
+		lines = append(lines, "  "+formatMysqlIndex(index))
+	}
+	for _, fk := range o.ForeignKeys {
+		lines = append(lines, "  "+formatMysqlForeignKey(fk))
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "CREATE TABLE %s (\n%s\n) %s", quoteMysqlIdent(o.Name), strings.Join(lines, ",\n"), o.formatOptions())
+	if o.Partition != nil {
+		sb.WriteString(formatMysqlPartition(o.Partition))
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// formatOptions 输出表选项，未指定时使用 InnoDB 及 utf8mb4
+func (o *mysqldbCreateTable) formatOptions() string {
+	engine, charset, collate := "InnoDB", mysqlDefaultCharset, ""
+	if o.Options != nil {
+		if v, found := o.Options.Get("engine"); found {
+			engine = v
+		}
+		if v := o.Options.Charset(); len(v) > 0 {
+			charset = v
+		}
+		collate = o.Options.Collate()
+	}
+
+	options := []string{"ENGINE=" + engine, "DEFAULT CHARSET=" + charset}
+	if len(collate) > 0 {
+		options = append(options, "COLLATE="+collate)
+	}
+	if len(o.Comment) > 0 {
+		options = append(options, "COMMENT="+quoteMysqlString(o.Comment))
+	}
+	return strings.Join(options, " ")
+}
+
+func formatMysqlColumn(column *Column) string {
+	var sb strings.Builder
+	sb.WriteString(quoteMysqlIdent(column.Name))
+	sb.WriteByte(' ')
+	sb.WriteString(formatMysqlColumnType(column.Type))
+
+	if generated := column.Generated; generated != nil {
+		kind := "VIRTUAL"
+		if generated.Stored {
+			kind = "STORED"
+		}
+		_, _ = fmt.Fprintf(&sb, " GENERATED ALWAYS AS (%s) %s", generated.Expr, kind)
+	}
+	if column.NotNull {
+		sb.WriteString(" NOT NULL")
+	}
+	if column.SRID > 0 {
+		_, _ = fmt.Fprintf(&sb, " SRID %d", column.SRID)
+	}
+	if column.AutoIncrement {
+		sb.WriteString(" AUTO_INCREMENT")
+	}
+	if column.Default != nil && column.Generated == nil {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(formatMysqlDefault(column.Default))
+	}
+	if len(column.OnUpdate) > 0 {
+		sb.WriteString(" ON UPDATE ")
+		sb.WriteString(column.OnUpdate)
+	}
+	if len(column.Comment) > 0 {
+		sb.WriteString(" COMMENT ")
+		sb.WriteString(quoteMysqlString(column.Comment))
+	}
+	return sb.String()
+}
+
+func formatMysqlColumnType(columnType ColumnType) string {
+	var sb strings.Builder
+	sb.WriteString(columnType.Name)
+	switch {
+	case len(columnType.Values) > 0:
+		var values []string
+		for _, v := range columnType.Values {
+			values = append(values, quoteMysqlString(v))
+		}
+		_, _ = fmt.Fprintf(&sb, "(%s)", strings.Join(values, ","))
+	case columnType.Length != nil && columnType.Scale != nil:
+		_, _ = fmt.Fprintf(&sb, "(%d,%d)", *columnType.Length, *columnType.Scale)
+	case columnType.Length != nil:
+		_, _ = fmt.Fprintf(&sb, "(%d)", *columnType.Length)
+	}
+	if columnType.Unsigned {
+		sb.WriteString(" unsigned")
+	}
+	if columnType.Zerofill {
+		sb.WriteString(" zerofill")
+	}
+	if len(columnType.Charset) > 0 {
+		sb.WriteString(" CHARACTER SET ")
+		sb.WriteString(columnType.Charset)
+	}
+	if len(columnType.Collate) > 0 {
+		sb.WriteString(" COLLATE ")
+		sb.WriteString(columnType.Collate)
+	}
+	return sb.String()
+}
+
+func formatMysqlDefault(def *DefaultValue) string {
+	switch def.Kind {
+	case DefaultNull:
+		return "NULL"
+	case DefaultNumber, DefaultCurrentTimestamp:
+		return def.Value
+	case DefaultBits:
+		return "b'" + def.Value + "'"
+	}
+	return quoteMysqlString(def.Value)
+}
+
+func formatMysqlIndex(index *Index) string {
+	var columns []string
+	for _, col := range index.Columns {
+		column := quoteMysqlIdent(col.Name)
+		if col.Length != nil {
+			column += "(" + strconv.Itoa(*col.Length) + ")"
+		}
+		columns = append(columns, column)
+	}
+	list := strings.Join(columns, ",")
+
+	switch index.Kind {
+	case IndexPrimary:
+		return fmt.Sprintf("PRIMARY KEY (%s)", list)
+	case IndexUnique:
+		return fmt.Sprintf("UNIQUE KEY %s (%s)", quoteMysqlIdent(index.Name), list)
+	case IndexFulltext:
+		if len(index.Parser) > 0 {
+			return fmt.Sprintf("FULLTEXT KEY %s (%s) WITH PARSER %s", quoteMysqlIdent(index.Name), list, index.Parser)
+		}
+		return fmt.Sprintf("FULLTEXT KEY %s (%s)", quoteMysqlIdent(index.Name), list)
+	case IndexSpatial:
+		return fmt.Sprintf("SPATIAL KEY %s (%s)", quoteMysqlIdent(index.Name), list)
+	}
+	return fmt.Sprintf("KEY %s (%s)", quoteMysqlIdent(index.Name), list)
+}
+
+func formatMysqlForeignKey(fk *ForeignKey) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteMysqlIdent(fk.Name),
+		joinMysqlIdents(fk.Columns),
+		quoteMysqlIdent(fk.RefTable),
+		joinMysqlIdents(fk.RefColumns))
+	if len(fk.OnDelete) > 0 {
+		sb.WriteString(" ON DELETE ")
+		sb.WriteString(fk.OnDelete)
+	}
+	if len(fk.OnUpdate) > 0 {
+		sb.WriteString(" ON UPDATE ")
+		sb.WriteString(fk.OnUpdate)
+	}
+	return sb.String()
+}
+
+func formatMysqlPartition(partition *Partition) string {
+	var sb strings.Builder
+	sb.WriteString("\nPARTITION BY ")
+	if partition.Linear {
+		sb.WriteString("LINEAR ")
+	}
+	sb.WriteString(partition.Method)
+	expr := partition.Expr
+	if partition.Columns {
+		sb.WriteString(" COLUMNS")
+		expr = joinMysqlIdents(parseIdentList(expr))
+	}
+	_, _ = fmt.Fprintf(&sb, "(%s)", expr)
+
+	if len(partition.Definitions) == 0 {
+		if partition.Partitions > 0 {
+			_, _ = fmt.Fprintf(&sb, " PARTITIONS %d", partition.Partitions)
+		}
+		return sb.String()
+	}
+	var definitions []string
+	for _, def := range partition.Definitions {
+		switch {
+		case def.MaxValue:
+			definitions = append(definitions, fmt.Sprintf("PARTITION %s VALUES LESS THAN MAXVALUE", quoteMysqlIdent(def.Name)))
+		case def.LessThan:
+			definitions = append(definitions, fmt.Sprintf("PARTITION %s VALUES LESS THAN (%s)", quoteMysqlIdent(def.Name), def.Values))
+		default:
+			definitions = append(definitions, fmt.Sprintf("PARTITION %s VALUES IN (%s)", quoteMysqlIdent(def.Name), def.Values))
+		}
+	}
+	_, _ = fmt.Fprintf(&sb, "\n(%s)", strings.Join(definitions, ",\n "))
+	return sb.String()
+}
+
+// mysqldbAlterTable ALTER TABLE 中的外键操作
+type mysqldbAlterTable struct {
+	*AlterTable
+}
+
+func (d *mysqldbAlterTable) Format() string {
+	var specs []string
+	for _, name := range d.DropForeignKeys {
+		specs = append(specs, "DROP FOREIGN KEY "+quoteMysqlIdent(name))
+	}
+	for _, fk := range d.AddForeignKeys {
+		specs = append(specs, "ADD "+formatMysqlForeignKey(fk))
+	}
+	if len(specs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s;", quoteMysqlIdent(d.Name), strings.Join(specs, ", "))
+}
+
+func quoteMysqlIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func joinMysqlIdents(names []string) string {
+	var quoted []string
+	for _, name := range names {
+		quoted = append(quoted, quoteMysqlIdent(name))
+	}
+	return strings.Join(quoted, ",")
+}
+
+// quoteMysqlString 输出 mysql 字符串字面量，反斜杠同样需要转义
+func quoteMysqlString(s string) string {
+	return quoteSQLString(strings.ReplaceAll(s, `\`, `\\`))
+}
@@ -0,0 +1,131 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMysqlDB_RoundTrip(t *testing.T) {
+	sql := "CREATE TABLE `reply` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`title` varchar(64) NOT NULL DEFAULT 'it''s' COMMENT '标题',\n" +
+		"`price` decimal(10,2) unsigned NOT NULL DEFAULT '0.00',\n" +
+		"`payload` json,\n" +
+		"`kind` enum('a','b') DEFAULT NULL,\n" +
+		"`tags` set('x','y.z') DEFAULT NULL,\n" +
+		"`flag` tinyint(1) NOT NULL DEFAULT '1',\n" +
+		"`location` point NOT NULL SRID 4326,\n" +
+		"`title_len` int GENERATED ALWAYS AS (char_length(`title`)) STORED,\n" +
+		"`draft_uuid` varchar(8) NOT NULL,\n" +
+		"`created_at` datetime DEFAULT CURRENT_TIMESTAMP,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"UNIQUE KEY `uk_title` (`title`),\n" +
+		"KEY `idx_title_prefix` (`title`(16)),\n" +
+		"FULLTEXT KEY `ft_title` (`title`) WITH PARSER ngram,\n" +
+		"SPATIAL KEY `sp_location` (`location`),\n" +
+		"CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`) ON DELETE CASCADE\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='回复'\n" +
+		"PARTITION BY RANGE (id) (PARTITION p0 VALUES LESS THAN (1000), PARTITION p1 VALUES LESS THAN MAXVALUE);\n" +
+		"DROP TABLE IF EXISTS `draft`;"
+
+	dm, err := NewDMDBWithOptions(sql, DMDBOptions{PrefixIndex: PrefixIndexSubstr}).Exec()
+	assert.Nil(t, err)
+
+	o := NewMysqlDB(dm)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "CREATE TABLE `reply` (\n  `id` bigint NOT NULL,\n")
+	// 达梦 DDL 中只有布尔列保留默认值
+	assert.Contains(t, got, "`title` varchar(64) NOT NULL COMMENT '标题',")
+	assert.Contains(t, got, "`price` decimal(10,2) unsigned NOT NULL,")
+	assert.Contains(t, got, "`payload` json,")
+	assert.Contains(t, got, "`kind` enum('a','b'),")
+	assert.Contains(t, got, "`tags` set('x','y.z'),")
+	assert.Contains(t, got, "`flag` tinyint(1) NOT NULL DEFAULT 1,")
+	assert.Contains(t, got, "`location` point NOT NULL SRID 4326,")
+	assert.Contains(t, got, "`title_len` int GENERATED ALWAYS AS (char_length(title)) STORED,")
+	assert.Contains(t, got, "`created_at` datetime,")
+	assert.Contains(t, got, "PRIMARY KEY (`id`),")
+	assert.Contains(t, got, "UNIQUE KEY `uk_title` (`title`),")
+	assert.Contains(t, got, "KEY `idx_title_prefix` (`title`(16)),")
+	assert.Contains(t, got, "FULLTEXT KEY `ft_title` (`title`) WITH PARSER ngram")
+	assert.Contains(t, got, "SPATIAL KEY `sp_location` (`location`)")
+	assert.Contains(t, got, ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='回复'\nPARTITION BY RANGE COLUMNS(`id`)")
+	assert.Contains(t, got, "PARTITION `p1` VALUES LESS THAN MAXVALUE);")
+	assert.Contains(t, got, "DROP TABLE IF EXISTS `draft`;")
+	assert.Contains(t, got, "ALTER TABLE `reply` ADD CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`) ON DELETE CASCADE;")
+
+	// 转换回 mysql 后再次转换为达梦，结果不变
+	again, err := NewDMDBWithOptions(got, DMDBOptions{PrefixIndex: PrefixIndexSubstr}).Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, dm, again)
+}
+
+func TestMysqlDB_HandEdited(t *testing.T) {
+	sql := `CREATE TABLE sysdba.orders (
+  id BIGINT IDENTITY(1, 1) NOT NULL,
+  amount NUMBER,
+  code VARCHAR(32) DEFAULT 'a''b',
+  created DATETIME DEFAULT CURRENT_TIMESTAMP,
+  note VARCHAR2(100) CHECK (LENGTH(note) > 2),
+  CONSTRAINT orders_pk PRIMARY KEY (id),
+  CONSTRAINT orders_code UNIQUE (code)
+);
+CREATE INDEX idx_orders_expr ON orders(LOWER(code));
+CREATE INDEX orders_amount ON orders(amount DESC);
+CREATE INDEX idx_missing_x ON missing(x);
+COMMENT ON COLUMN orders.code IS '编码';
+ALTER TABLE orders DROP CONSTRAINT fk_orders_customer;
+GRANT SELECT ON orders TO app;`
+
+	o := NewMysqlDB(sql)
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "`id` bigint NOT NULL AUTO_INCREMENT,")
+	assert.Contains(t, got, "`amount` double,")
+	assert.Contains(t, got, "`code` varchar(32) DEFAULT 'a''b' COMMENT '编码',")
+	assert.Contains(t, got, "`created` datetime DEFAULT CURRENT_TIMESTAMP,")
+	assert.Contains(t, got, "`note` varchar(100),")
+	assert.Contains(t, got, "PRIMARY KEY (`id`),")
+	assert.Contains(t, got, "UNIQUE KEY `orders_code` (`code`),")
+	assert.Contains(t, got, "KEY `orders_amount` (`amount`)")
+	assert.NotContains(t, got, "expr")
+	assert.Contains(t, got, "ALTER TABLE `orders` DROP FOREIGN KEY `customer`;")
+
+	var objects []string
+	for _, d := range o.Diagnostics() {
+		objects = append(objects, d.Object)
+	}
+	assert.Equal(t, []string{"amount", "note", "idx_orders_expr", "idx_missing_x", ""}, objects)
+}
+
+func Test_splitDMDBStatements(t *testing.T) {
+	sql := "CREATE TABLE t1 (a int);\n" +
+		"COMMENT ON TABLE t1 IS 'a;b';\n/\n" +
+		"CREATE OR REPLACE TRIGGER trg_t1_a\nBEFORE INSERT ON t1\nFOR EACH ROW\nBEGIN\n" +
+		"   :NEW.a := CASE WHEN :NEW.a IS NULL THEN 0 ELSE :NEW.a END;\nEND;\n" +
+		"BEGIN\n   EXECUTE IMMEDIATE 'DROP TABLE t2';\nEXCEPTION\n   WHEN OTHERS THEN NULL;\nEND;\n/\n" +
+		"CREATE INDEX idx_t1_a ON t1(a)"
+
+	statements := splitDMDBStatements(sql)
+	assert.Equal(t, 5, len(statements))
+	assert.Equal(t, "COMMENT ON TABLE t1 IS 'a;b'", statements[1])
+	assert.Contains(t, statements[2], "END;")
+	assert.Contains(t, statements[3], "DROP TABLE t2")
+	assert.Equal(t, "CREATE INDEX idx_t1_a ON t1(a)", statements[4])
+}
+
+func TestMysqlDB_SetValuesWithPipe(t *testing.T) {
+	sql := "CREATE TABLE `reply` (`tags` set('a|b','c','it''s|x.y') DEFAULT NULL) ENGINE=InnoDB;"
+
+	dm, err := NewDMDBFromSQL(sql).Exec()
+	assert.Nil(t, err)
+	got, err := NewMysqlDB(dm).Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "`tags` set('a|b','c','it''s|x.y')")
+
+	again, err := NewDMDBFromSQL(got).Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, dm, again)
+}
@@ -0,0 +1,187 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrefixIndexStrategy 前缀索引（如 KEY idx (col(10))）的转换策略
+// 达梦不支持前缀索引，需要在转换时选择一种替代方式
+type PrefixIndexStrategy int
+
+const (
+	// PrefixIndexFullColumn 忽略前缀长度，对整列建立索引
+	PrefixIndexFullColumn PrefixIndexStrategy = iota
+	// PrefixIndexSubstr 建立 SUBSTR(col,1,n) 函数索引
+	PrefixIndexSubstr
+	// PrefixIndexSkip 跳过该索引，并给出警告
+	PrefixIndexSkip
+)
+
+var prefixIndexStrategyNames = map[PrefixIndexStrategy]string{
+	PrefixIndexFullColumn: "full",
+	PrefixIndexSubstr:     "substr",
+	PrefixIndexSkip:       "skip",
+}
+
+func (s PrefixIndexStrategy) String() string {
+	if name, found := prefixIndexStrategyNames[s]; found {
+		return name
+	}
+	return fmt.Sprintf("PrefixIndexStrategy(%d)", int(s))
+}
+
+// ParsePrefixIndexStrategy 将 full/substr/skip 解析为对应的策略
+func ParsePrefixIndexStrategy(name string) (PrefixIndexStrategy, error) {
+	for s, n := range prefixIndexStrategyNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown prefix index strategy '%s'", name)
+}
+
+// CaseInsensitiveUniqueStrategy 唯一索引中大小写不敏感（*_ci 排序规则）的列的转换策略
+type CaseInsensitiveUniqueStrategy int
+
+const (
+	// CaseInsensitiveUpper 对这些列建立 UPPER(col) 函数索引
+	CaseInsensitiveUpper CaseInsensitiveUniqueStrategy = iota
+	// CaseInsensitiveReport 保持原索引，在提示中列出这些索引
+	CaseInsensitiveReport
+	// CaseInsensitiveIgnore 不做处理，适用于以大小写不敏感方式初始化（CASE_SENSITIVE=N）的目标库
+	CaseInsensitiveIgnore
+)
+
+var caseInsensitiveUniqueStrategyNames = map[CaseInsensitiveUniqueStrategy]string{
+	CaseInsensitiveUpper:  "upper",
+	CaseInsensitiveReport: "report",
+	CaseInsensitiveIgnore: "ignore",
+}
+
+func (s CaseInsensitiveUniqueStrategy) String() string {
+	if name, found := caseInsensitiveUniqueStrategyNames[s]; found {
+		return name
+	}
+	return fmt.Sprintf("CaseInsensitiveUniqueStrategy(%d)", int(s))
+}
+
+// ParseCaseInsensitiveUniqueStrategy 将 upper/report/ignore 解析为对应的策略
+func ParseCaseInsensitiveUniqueStrategy(name string) (CaseInsensitiveUniqueStrategy, error) {
+	for s, n := range caseInsensitiveUniqueStrategyNames {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown case-insensitive unique strategy '%s'", name)
+}
+
+// TinyintBoolMapping tinyint(1) 的转换方式，mysql 中 tinyint(1) 一般用作布尔值
+type TinyintBoolMapping int
+
+const (
+	// TinyintBoolBit 转换为 BIT
+	TinyintBoolBit TinyintBoolMapping = iota
+	// TinyintBoolBoolean 转换为 BOOLEAN
+	TinyintBoolBoolean
+	// TinyintBoolNumber 与其他宽度的 tinyint 相同，按整数转换
+	TinyintBoolNumber
+)
+
+var tinyintBoolMappingNames = map[TinyintBoolMapping]string{
+	TinyintBoolBit:     "bit",
+	TinyintBoolBoolean: "boolean",
+	TinyintBoolNumber:  "tinyint",
+}
+
+func (m TinyintBoolMapping) String() string {
+	if name, found := tinyintBoolMappingNames[m]; found {
+		return name
+	}
+	return fmt.Sprintf("TinyintBoolMapping(%d)", int(m))
+}
+
+// ParseTinyintBoolMapping 将 bit/boolean/tinyint 解析为对应的转换方式
+func ParseTinyintBoolMapping(name string) (TinyintBoolMapping, error) {
+	for m, n := range tinyintBoolMappingNames {
+		if strings.EqualFold(n, name) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tinyint(1) mapping '%s'", name)
+}
+
+// SpatialTypeMapping 空间类型（GEOMETRY、POINT 等）的转换方式
+type SpatialTypeMapping int
+
+const (
+	// SpatialSysgeo 转换为达梦 SYSGEO.ST_GEOMETRY 系列类型，需要目标库已初始化空间数据支持
+	SpatialSysgeo SpatialTypeMapping = iota
+	// SpatialWKB 转换为 BLOB，按 WKB 格式存储
+	SpatialWKB
+)
+
+var spatialTypeMappingNames = map[SpatialTypeMapping]string{
+	SpatialSysgeo: "sysgeo",
+	SpatialWKB:    "wkb",
+}
+
+func (m SpatialTypeMapping) String() string {
+	if name, found := spatialTypeMappingNames[m]; found {
+		return name
+	}
+	return fmt.Sprintf("SpatialTypeMapping(%d)", int(m))
+}
+
+// ParseSpatialTypeMapping 将 sysgeo/wkb 解析为对应的转换方式
+func ParseSpatialTypeMapping(name string) (SpatialTypeMapping, error) {
+	for m, n := range spatialTypeMappingNames {
+		if strings.EqualFold(n, name) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown spatial type mapping '%s'", name)
+}
+
+// DMDBOptions 单次转换的选项，零值即默认行为
+type DMDBOptions struct {
+	// PrefixIndex 前缀索引的转换策略
+	PrefixIndex PrefixIndexStrategy
+	// SkipFulltextIndex 目标库未启用全文检索时，跳过 FULLTEXT 索引
+	SkipFulltextIndex bool
+	// SkipSpatialIndex 目标库未安装空间数据支持时，跳过 SPATIAL 索引
+	SkipSpatialIndex bool
+	// StoredGeneratedAsVirtual 将 STORED 生成列转换为达梦的虚拟列，默认使用普通列加触发器维护
+	StoredGeneratedAsVirtual bool
+	// CharLength 多字节字符集的 char/varchar 列的长度语义
+	CharLength CharLengthSemantics
+	// TargetCharset 目标库的字符集（DMCharsetUTF8 或 DMCharsetGB18030），为空时为 UTF-8
+	TargetCharset string
+	// CaseInsensitiveUnique 唯一索引中 *_ci 排序规则的列的转换策略
+	CaseInsensitiveUnique CaseInsensitiveUniqueStrategy
+	// TinyintBool tinyint(1) 列的转换方式
+	TinyintBool TinyintBoolMapping
+	// TinyintBoolOverrides 按表（key 为表名）或列（key 为 表名.列名）覆盖 TinyintBool，不区分大小写
+	TinyintBoolOverrides map[string]TinyintBoolMapping
+	// SpatialType 空间类型的转换方式
+	SpatialType SpatialTypeMapping
+	// TextAsVarchar 将 tinytext 转换为 VARCHAR2，使其可以建立普通索引，默认转换为 TEXT
+	TextAsVarchar bool
+	// TypeMapping 覆盖内置的类型映射，为 nil 时使用内置的映射
+	TypeMapping *TypeMapping
+}
+
+// tinyintBool 返回指定列的 tinyint(1) 转换方式，列的设置优先于表的设置
+func (o DMDBOptions) tinyintBool(tableName, columnName string) TinyintBoolMapping {
+	for key, m := range o.TinyintBoolOverrides {
+		if strings.EqualFold(key, tableName+"."+columnName) {
+			return m
+		}
+	}
+	for key, m := range o.TinyintBoolOverrides {
+		if strings.EqualFold(key, tableName) {
+			return m
+		}
+	}
+	return o.TinyintBool
+}
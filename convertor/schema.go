@@ -0,0 +1,230 @@
+package convertor
+
+import (
+	"strings"
+)
+
+// Schema 从 mysql DDL 解析得到的、与目标数据库无关的结构，语句按原有顺序保存
+// 各目标数据库的转换都基于 Schema 输出，不再直接访问 sqlparser 的语法树
+type Schema struct {
+	Statements []*Statement `json:"statements"`
+	// SkippedTables 无法解析而被跳过的 CREATE TABLE 的表名
+	SkippedTables []string `json:"skipped_tables,omitempty"`
+}
+
+// Skipped 表的 CREATE TABLE 无法解析而被跳过
+func (s *Schema) Skipped(name string) bool {
+	for _, skipped := range s.SkippedTables {
+		if strings.EqualFold(skipped, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tables 返回 CREATE TABLE 语句中的表
+func (s *Schema) Tables() []*Table {
+	var tables []*Table
+	for _, stmt := range s.Statements {
+		if stmt.Kind == StatementCreateTable {
+			tables = append(tables, stmt.Table)
+		}
+	}
+	return tables
+}
+
+// StatementKind 语句类型
+type StatementKind string
+
+const (
+	StatementCreateTable StatementKind = "create_table"
+	StatementDropTable   StatementKind = "drop_table"
+	StatementAlterTable  StatementKind = "alter_table"
+)
+
+// Statement 单条语句，按 Kind 使用 Table、Drop 或 Alter
+type Statement struct {
+	Kind  StatementKind `json:"kind"`
+	Table *Table        `json:"table,omitempty"`
+	Drop  *DropTable    `json:"drop,omitempty"`
+	Alter *AlterTable   `json:"alter,omitempty"`
+}
+
+// DropTable DROP TABLE [IF EXISTS]
+type DropTable struct {
+	Name     string `json:"name"`
+	IfExists bool   `json:"if_exists"`
+}
+
+// AlterTable ALTER TABLE 中可以转换的操作：外键及分区
+type AlterTable struct {
+	Name            string            `json:"name"`
+	AddForeignKeys  []*ForeignKey     `json:"add_foreign_keys,omitempty"`
+	DropForeignKeys []string          `json:"drop_foreign_keys,omitempty"`
+	Partitions      []*AlterPartition `json:"partitions,omitempty"`
+}
+
+// Table CREATE TABLE
+type Table struct {
+	Name        string        `json:"name"`
+	Columns     []*Column     `json:"columns"`
+	Indexes     []*Index      `json:"indexes"`
+	ForeignKeys []*ForeignKey `json:"foreign_keys,omitempty"`
+	Options     *TableOptions `json:"options,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+	Partition   *Partition    `json:"partition,omitempty"`
+}
+
+// Column 忽略大小写查找列，不存在时返回 nil
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column
+		}
+	}
+	return nil
+}
+
+// PrimaryKey 返回主键，没有主键时返回 nil
+func (t *Table) PrimaryKey() *Index {
+	for _, index := range t.Indexes {
+		if index.Kind == IndexPrimary {
+			return index
+		}
+	}
+	return nil
+}
+
+// Column 列
+type Column struct {
+	Name          string           `json:"name"`
+	Type          ColumnType       `json:"type"`
+	NotNull       bool             `json:"not_null"`
+	AutoIncrement bool             `json:"auto_increment,omitempty"`
+	Default       *DefaultValue    `json:"default,omitempty"`
+	OnUpdate      string           `json:"on_update,omitempty"` // ON UPDATE CURRENT_TIMESTAMP，未指定时为空
+	Comment       string           `json:"comment,omitempty"`
+	Generated     *GeneratedColumn `json:"generated,omitempty"` // 生成列
+	SRID          int              `json:"srid,omitempty"`      // 空间类型列的 SRID，未指定时为 0
+}
+
+// ColumnType 列类型，Name 为小写的 mysql 类型名
+type ColumnType struct {
+	Name     string   `json:"name"`
+	Length   *int     `json:"length,omitempty"`
+	Scale    *int     `json:"scale,omitempty"`
+	Unsigned bool     `json:"unsigned,omitempty"`
+	Zerofill bool     `json:"zerofill,omitempty"`
+	Charset  string   `json:"charset,omitempty"` // 小写，未指定时为空
+	Collate  string   `json:"collate,omitempty"` // 小写，未指定时为空
+	Values   []string `json:"values,omitempty"`  // ENUM/SET 的取值，不含引号
+}
+
+// LengthOr 返回列的长度，未指定长度时返回 def
+func (t ColumnType) LengthOr(def int) int {
+	if t.Length == nil {
+		return def
+	}
+	return *t.Length
+}
+
+// DefaultKind 默认值的类型
+type DefaultKind string
+
+const (
+	DefaultNull             DefaultKind = "null"
+	DefaultString           DefaultKind = "string"
+	DefaultNumber           DefaultKind = "number"
+	DefaultBits             DefaultKind = "bits" // b'0101'，x'..' 及 0x.. 在预处理时改写为位串
+	DefaultCurrentTimestamp DefaultKind = "current_timestamp"
+)
+
+// DefaultValue 列的默认值，Value 为不含引号的原值
+type DefaultValue struct {
+	Kind  DefaultKind `json:"kind"`
+	Value string      `json:"value,omitempty"`
+}
+
+// GeneratedColumn 生成列 [GENERATED ALWAYS] AS (expr) [VIRTUAL|STORED]
+type GeneratedColumn struct {
+	Expr   string `json:"expr"`
+	Stored bool   `json:"stored"`
+}
+
+// IndexKind 索引类型
+type IndexKind string
+
+const (
+	IndexPrimary  IndexKind = "primary"
+	IndexUnique   IndexKind = "unique"
+	IndexNormal   IndexKind = "index"
+	IndexFulltext IndexKind = "fulltext"
+	IndexSpatial  IndexKind = "spatial"
+)
+
+// Index 索引，主键的 Name 为 PRIMARY
+type Index struct {
+	Name    string         `json:"name"`
+	Kind    IndexKind      `json:"kind"`
+	Columns []*IndexColumn `json:"columns"`
+	Parser  string         `json:"parser,omitempty"` // 全文索引的 WITH PARSER
+}
+
+// Unique 主键和唯一索引返回 true
+func (i *Index) Unique() bool {
+	return i.Kind == IndexPrimary || i.Kind == IndexUnique
+}
+
+// ColumnNames 返回索引列的列名
+func (i *Index) ColumnNames() []string {
+	var names []string
+	for _, col := range i.Columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// IndexColumn 索引列，Length 为前缀索引的长度
+type IndexColumn struct {
+	Name   string `json:"name"`
+	Length *int   `json:"length,omitempty"`
+}
+
+// ForeignKey 外键约束，OnDelete、OnUpdate 为空表示未指定
+type ForeignKey struct {
+	Name       string   `json:"name"`
+	Table      string   `json:"table"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns"`
+	OnDelete   string   `json:"on_delete,omitempty"`
+	OnUpdate   string   `json:"on_update,omitempty"`
+}
+
+// Partition PARTITION BY 子句
+type Partition struct {
+	Method       string                 `json:"method"` // RANGE、LIST、HASH、KEY
+	Linear       bool                   `json:"linear,omitempty"`
+	Columns      bool                   `json:"columns,omitempty"`      // RANGE COLUMNS / LIST COLUMNS
+	Expr         string                 `json:"expr,omitempty"`         // 分区表达式，一般为列名
+	Partitions   int                    `json:"partitions,omitempty"`   // PARTITIONS n，未指定时为 0
+	Subpartition bool                   `json:"subpartition,omitempty"` // 包含 SUBPARTITION BY
+	Definitions  []*PartitionDefinition `json:"definitions,omitempty"`
+}
+
+// PartitionDefinition 分区定义
+type PartitionDefinition struct {
+	Name     string `json:"name"`
+	LessThan bool   `json:"less_than,omitempty"` // VALUES LESS THAN，否则为 VALUES IN
+	Values   string `json:"values,omitempty"`    // 括号内的值，MAXVALUE 时为空
+	MaxValue bool   `json:"max_value,omitempty"`
+}
+
+// AlterPartition ALTER TABLE 中的 ADD/DROP/TRUNCATE PARTITION
+type AlterPartition struct {
+	Action      string                 `json:"action"`
+	Names       []string               `json:"names,omitempty"`      // DROP/TRUNCATE 的分区名
+	All         bool                   `json:"all,omitempty"`        // TRUNCATE PARTITION ALL
+	Partitions  int                    `json:"partitions,omitempty"` // ADD PARTITION PARTITIONS n
+	Definitions []*PartitionDefinition `json:"definitions,omitempty"`
+}
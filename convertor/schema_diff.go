@@ -0,0 +1,247 @@
+package convertor
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaDiff 两个 Schema 中的表结构差异，表、列、索引、外键均按名字（忽略大小写）对应，
+// 重命名按删除后新增处理
+type SchemaDiff struct {
+	CreateTables []*Table
+	DropTables   []*Table
+	AlterTables  []*TableDiff
+}
+
+// TableDiff 同名表之间的差异
+type TableDiff struct {
+	Old, New        *Table
+	AddColumns      []*Column
+	DropColumns     []*Column
+	ModifyColumns   []*ColumnDiff // 定义、注释或实际使用的排序规则发生变化的列
+	AddIndexes      []*Index
+	DropIndexes     []*Index // 修改的索引按删除后新增处理
+	AddForeignKeys  []*ForeignKey
+	DropForeignKeys []*ForeignKey
+	Options         []*OptionDiff // 除 comment 之外的表选项的变化
+}
+
+// ColumnDiff 同名列之间的差异
+type ColumnDiff struct {
+	Old, New *Column
+}
+
+// DefinitionChanged 列定义（除注释之外）发生变化
+func (d *ColumnDiff) DefinitionChanged() bool {
+	oldColumn, newColumn := *d.Old, *d.New
+	oldColumn.Comment, newColumn.Comment = "", ""
+	// 列名的大小写不同不视为修改
+	oldColumn.Name = newColumn.Name
+	return !reflect.DeepEqual(oldColumn, newColumn)
+}
+
+// CommentChanged 列注释发生变化
+func (d *ColumnDiff) CommentChanged() bool {
+	return d.Old.Comment != d.New.Comment
+}
+
+// OptionDiff 表选项的变化，Old 或 New 为空表示新增或删除该选项
+type OptionDiff struct {
+	Key      string
+	Old, New string
+}
+
+// CommentChanged 表注释发生变化
+func (d *TableDiff) CommentChanged() bool {
+	return d.Old.Comment != d.New.Comment
+}
+
+// collationChanged 字符串列实际使用的排序规则发生变化，列未设置字符集时随表的默认字符集、排序规则变化
+func (d *TableDiff) collationChanged(c *ColumnDiff) bool {
+	if _, found := mysqlStringTypes[c.New.Type.Name]; !found {
+		return false
+	}
+	return columnCollation(c.Old.Type, d.Old.Options) != columnCollation(c.New.Type, d.New.Options)
+}
+
+// PartitionChanged 分区定义发生变化
+func (d *TableDiff) PartitionChanged() bool {
+	return !reflect.DeepEqual(d.Old.Partition, d.New.Partition)
+}
+
+// Empty 两个表没有差异
+func (d *TableDiff) Empty() bool {
+	return len(d.AddColumns) == 0 && len(d.DropColumns) == 0 && len(d.ModifyColumns) == 0 &&
+		len(d.AddIndexes) == 0 && len(d.DropIndexes) == 0 &&
+		len(d.AddForeignKeys) == 0 && len(d.DropForeignKeys) == 0 &&
+		len(d.Options) == 0 && !d.CommentChanged() && !d.PartitionChanged()
+}
+
+// DiffSchema 比较 oldSchema、newSchema 中 CREATE TABLE 的表，返回从 oldSchema 迁移到 newSchema 需要的变化，
+// 任一侧无法解析而被跳过的表不比较，既不新建也不删除
+func DiffSchema(oldSchema, newSchema *Schema) *SchemaDiff {
+	var diff = &SchemaDiff{}
+	oldTables := oldSchema.Tables()
+	newTables := newSchema.Tables()
+
+	for _, newTable := range newTables {
+		oldTable := findTable(oldTables, newTable.Name)
+		if oldTable == nil {
+			if oldSchema.Skipped(newTable.Name) {
+				continue
+			}
+			diff.CreateTables = append(diff.CreateTables, newTable)
+			continue
+		}
+		if tableDiff := diffTable(oldTable, newTable); !tableDiff.Empty() {
+			diff.AlterTables = append(diff.AlterTables, tableDiff)
+		}
+	}
+	for _, oldTable := range oldTables {
+		if findTable(newTables, oldTable.Name) == nil && !newSchema.Skipped(oldTable.Name) {
+			diff.DropTables = append(diff.DropTables, oldTable)
+		}
+	}
+	return diff
+}
+
+func diffTable(oldTable, newTable *Table) *TableDiff {
+	var diff = &TableDiff{Old: oldTable, New: newTable}
+
+	for _, newColumn := range newTable.Columns {
+		oldColumn := oldTable.Column(newColumn.Name)
+		if oldColumn == nil {
+			diff.AddColumns = append(diff.AddColumns, newColumn)
+			continue
+		}
+		columnDiff := &ColumnDiff{Old: oldColumn, New: newColumn}
+		if columnDiff.DefinitionChanged() || columnDiff.CommentChanged() || diff.collationChanged(columnDiff) {
+			diff.ModifyColumns = append(diff.ModifyColumns, columnDiff)
+		}
+	}
+	for _, oldColumn := range oldTable.Columns {
+		if newTable.Column(oldColumn.Name) == nil {
+			diff.DropColumns = append(diff.DropColumns, oldColumn)
+		}
+	}
+
+	for _, newIndex := range newTable.Indexes {
+		oldIndex := findIndex(oldTable.Indexes, newIndex)
+		if oldIndex != nil && sameIndex(oldIndex, newIndex) {
+			continue
+		}
+		if oldIndex != nil {
+			diff.DropIndexes = append(diff.DropIndexes, oldIndex)
+		}
+		diff.AddIndexes = append(diff.AddIndexes, newIndex)
+	}
+	for _, oldIndex := range oldTable.Indexes {
+		if findIndex(newTable.Indexes, oldIndex) == nil {
+			diff.DropIndexes = append(diff.DropIndexes, oldIndex)
+		}
+	}
+
+	for _, newFK := range newTable.ForeignKeys {
+		oldFK := findForeignKey(oldTable.ForeignKeys, newFK.Name)
+		if oldFK != nil && reflect.DeepEqual(oldFK, newFK) {
+			continue
+		}
+		if oldFK != nil {
+			diff.DropForeignKeys = append(diff.DropForeignKeys, oldFK)
+		}
+		diff.AddForeignKeys = append(diff.AddForeignKeys, newFK)
+	}
+	for _, oldFK := range oldTable.ForeignKeys {
+		if findForeignKey(newTable.ForeignKeys, oldFK.Name) == nil {
+			diff.DropForeignKeys = append(diff.DropForeignKeys, oldFK)
+		}
+	}
+
+	diff.Options = diffTableOptions(oldTable.Options, newTable.Options)
+	return diff
+}
+
+func diffTableOptions(oldOptions, newOptions *TableOptions) []*OptionDiff {
+	var oldMap, newMap = map[string]string{}, map[string]string{}
+	if oldOptions != nil {
+		oldMap = oldOptions.Map()
+	}
+	if newOptions != nil {
+		newMap = newOptions.Map()
+	}
+
+	var result []*OptionDiff
+	for _, key := range sortedKeys(oldMap, newMap) {
+		if key == "comment" {
+			continue
+		}
+		if oldMap[key] != newMap[key] {
+			result = append(result, &OptionDiff{Key: key, Old: oldMap[key], New: newMap[key]})
+		}
+	}
+	return result
+}
+
+// sortedKeys 返回多个 map 中全部的 key，按字典序排列
+func sortedKeys(maps ...map[string]string) []string {
+	var keys []string
+	seen := map[string]struct{}{}
+	for _, m := range maps {
+		for key := range m {
+			if _, found := seen[key]; !found {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func findTable(tables []*Table, name string) *Table {
+	for _, table := range tables {
+		if strings.EqualFold(table.Name, name) {
+			return table
+		}
+	}
+	return nil
+}
+
+// findIndex 主键按类型对应，其他索引按名字对应
+func findIndex(indexes []*Index, index *Index) *Index {
+	for _, i := range indexes {
+		if index.Kind == IndexPrimary || i.Kind == IndexPrimary {
+			if index.Kind == i.Kind {
+				return i
+			}
+			continue
+		}
+		if strings.EqualFold(i.Name, index.Name) {
+			return i
+		}
+	}
+	return nil
+}
+
+// sameIndex 索引定义相同，名字和列名忽略大小写
+func sameIndex(a, b *Index) bool {
+	if a.Kind != b.Kind || a.Parser != b.Parser || !strings.EqualFold(a.Name, b.Name) || len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i, column := range a.Columns {
+		if !strings.EqualFold(column.Name, b.Columns[i].Name) || !reflect.DeepEqual(column.Length, b.Columns[i].Length) {
+			return false
+		}
+	}
+	return true
+}
+
+func findForeignKey(foreignKeys []*ForeignKey, name string) *ForeignKey {
+	for _, fk := range foreignKeys {
+		if strings.EqualFold(fk.Name, name) {
+			return fk
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package convertor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaJSONVersion JSON 结构的版本，结构发生不兼容的变化时递增
+const SchemaJSONVersion = 1
+
+// SchemaDocument JSON 输出的顶层结构，字段说明见 README 的 JSON 一节
+type SchemaDocument struct {
+	Version int      `json:"version"`
+	Tables  []*Table `json:"tables"`
+}
+
+// ToJSON 输出 Schema 中全部 CREATE TABLE 的表
+func (s *Schema) ToJSON() (string, error) {
+	tables := s.Tables()
+	if tables == nil {
+		tables = []*Table{}
+	}
+	data, err := json.MarshalIndent(&SchemaDocument{Version: SchemaJSONVersion, Tables: tables}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ParseSchemaJSON 读取 ToJSON 的输出
+func ParseSchemaJSON(data []byte) (*SchemaDocument, error) {
+	var doc SchemaDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != SchemaJSONVersion {
+		return nil, fmt.Errorf("unsupported schema json version %d", doc.Version)
+	}
+	return &doc, nil
+}
@@ -0,0 +1,48 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_ToJSON(t *testing.T) {
+	sql := "CREATE TABLE `reply` (\n" +
+		"`id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"`title` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT 'untitled' COMMENT '标题',\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"KEY `idx_title` (`title`(16))\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 COMMENT='回复';\n" +
+		"DROP TABLE IF EXISTS `draft`;"
+
+	schema, _ := ParseMysqlSchema(sql)
+	got, err := schema.ToJSON()
+	assert.Nil(t, err)
+	assert.Contains(t, got, `"version": 1`)
+	assert.Contains(t, got, `"charset": "utf8mb4"`)
+	assert.NotContains(t, got, "draft")
+
+	doc, err := ParseSchemaJSON([]byte(got))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(doc.Tables))
+	table := doc.Tables[0]
+	assert.Equal(t, "回复", table.Comment)
+	assert.Equal(t, map[string]string{"engine": "InnoDB", "charset": "utf8mb4", "comment": "回复"}, table.Options.Map())
+	assert.Equal(t, "utf8mb4", table.Options.Charset())
+
+	title := table.Column("title")
+	assert.Equal(t, 64, title.Type.LengthOr(0))
+	assert.Equal(t, "utf8mb4", title.Type.Charset)
+	assert.Equal(t, "utf8mb4_bin", title.Type.Collate)
+	assert.False(t, title.NotNull)
+	assert.Equal(t, &DefaultValue{Kind: DefaultString, Value: "untitled"}, title.Default)
+	assert.Equal(t, "标题", title.Comment)
+	assert.Equal(t, schema.Tables()[0].Indexes, table.Indexes)
+
+	empty, err := (&Schema{}).ToJSON()
+	assert.Nil(t, err)
+	assert.Contains(t, empty, `"tables": []`)
+
+	_, err = ParseSchemaJSON([]byte(`{"version": 0, "tables": []}`))
+	assert.NotNil(t, err)
+}
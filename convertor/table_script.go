@@ -0,0 +1,97 @@
+package convertor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableScriptManifest 按表拆分输出时清单文件的文件名
+const TableScriptManifest = "manifest.txt"
+
+// 外键单独成为脚本：删除外键最先执行，添加外键在所有表创建完成之后执行
+const (
+	dropForeignKeysScript = "drop_foreign_keys"
+	foreignKeysScript     = "foreign_keys"
+)
+
+// TableScript 按表拆分的转换结果
+type TableScript struct {
+	Table    string // 外键脚本为空
+	FileName string // 如 draft.sql，只含字母、数字及下划线
+	Objects  []*DMDBObject
+}
+
+// SQL 返回脚本的内容，语句之间以 / 分隔
+func (s *TableScript) SQL() string {
+	var statements []string
+	for _, object := range s.Objects {
+		statements = append(statements, object.SQL)
+	}
+	return strings.Join(statements, "\n/\n") + "\n"
+}
+
+// TableScripts 将转换结果按表拆分为脚本，每个表一个脚本，包含表的删除、创建、主键、索引、注释、触发器及分区操作，
+// 按表首次出现的顺序排列。删除外键的语句放在最前面的 drop_foreign_keys.sql 中，添加外键的语句放在最后的 foreign_keys.sql 中，
+// 因此各表的脚本之间没有依赖
+func TableScripts(objects []*DMDBObject) []*TableScript {
+	var dropForeignKeys, foreignKeys *TableScript
+	var tables []*TableScript
+	scripts := map[string]*TableScript{}
+	fileNames := map[string]bool{dropForeignKeysScript: true, foreignKeysScript: true}
+
+	for _, object := range objects {
+		switch object.Kind {
+		case DMDBObjectDropForeignKey:
+			if dropForeignKeys == nil {
+				dropForeignKeys = &TableScript{FileName: dropForeignKeysScript + ".sql"}
+			}
+			dropForeignKeys.Objects = append(dropForeignKeys.Objects, object)
+		case DMDBObjectForeignKey:
+			if foreignKeys == nil {
+				foreignKeys = &TableScript{FileName: foreignKeysScript + ".sql"}
+			}
+			foreignKeys.Objects = append(foreignKeys.Objects, object)
+		default:
+			script, found := scripts[object.Table]
+			if !found {
+				script = &TableScript{Table: object.Table, FileName: tableScriptFileName(object.Table, fileNames)}
+				scripts[object.Table] = script
+				tables = append(tables, script)
+			}
+			script.Objects = append(script.Objects, object)
+		}
+	}
+
+	var result []*TableScript
+	if dropForeignKeys != nil {
+		result = append(result, dropForeignKeys)
+	}
+	result = append(result, tables...)
+	if foreignKeys != nil {
+		result = append(result, foreignKeys)
+	}
+	return result
+}
+
+// tableScriptFileName 返回表的脚本文件名，文件名不区分大小写，重复时添加序号
+func tableScriptFileName(table string, fileNames map[string]bool) string {
+	name := strings.Trim(flywayDescriptionRegexp.ReplaceAllString(table, "_"), "_")
+	if len(name) == 0 {
+		name = "table"
+	}
+	fileName := name
+	for i := 2; fileNames[strings.ToLower(fileName)]; i++ {
+		fileName = fmt.Sprintf("%s_%d", name, i)
+	}
+	fileNames[strings.ToLower(fileName)] = true
+	return fileName + ".sql"
+}
+
+// TableScriptsManifest 返回清单文件的内容，每行一个脚本的文件名，按执行顺序排列
+func TableScriptsManifest(scripts []*TableScript) string {
+	var sb strings.Builder
+	for _, script := range scripts {
+		sb.WriteString(script.FileName + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableScripts(t *testing.T) {
+	sql := "ALTER TABLE `comment` DROP FOREIGN KEY `comment_user`;\n" +
+		"DROP TABLE IF EXISTS `reply`;\n" +
+		"CREATE TABLE `reply` (`id` bigint NOT NULL, `draft_uuid` varchar(8) NOT NULL COMMENT '草稿', PRIMARY KEY (`id`), " +
+		"KEY `idx_draft` (`draft_uuid`), CONSTRAINT `reply_draft` FOREIGN KEY (`draft_uuid`) REFERENCES `draft` (`uuid`));\n" +
+		"CREATE TABLE `draft` (`uuid` varchar(8) NOT NULL);\n" +
+		"CREATE TABLE `Draft` (`uuid` varchar(8) NOT NULL);"
+
+	objects, err := NewDMDBFromSQL(sql).Objects()
+	assert.Nil(t, err)
+	scripts := TableScripts(objects)
+	var names, tables []string
+	for _, script := range scripts {
+		names = append(names, script.FileName)
+		tables = append(tables, script.Table)
+	}
+	assert.Equal(t, []string{"drop_foreign_keys.sql", "reply.sql", "draft.sql", "Draft_2.sql", "foreign_keys.sql"}, names)
+	assert.Equal(t, []string{"", "reply", "draft", "Draft", ""}, tables)
+
+	assert.Equal(t, "ALTER TABLE \"comment\" DROP CONSTRAINT fk_comment_comment_user;\n", scripts[0].SQL())
+	assert.Equal(t, "BEGIN\n   EXECUTE IMMEDIATE 'DROP TABLE reply';\nEXCEPTION\n   WHEN OTHERS THEN NULL;\nEND;\n/\n"+
+		"CREATE TABLE reply (\nid bigint NOT NULL ,\ndraft_uuid varchar2(8 CHAR) NOT NULL );\n/\n"+
+		"ALTER TABLE reply ADD CONSTRAINT pk_reply_id PRIMARY KEY (id);\n/\n"+
+		"CREATE INDEX idx_reply_idx_draft ON reply(draft_uuid);\n/\n"+
+		"COMMENT ON COLUMN reply.draft_uuid IS '草稿';\n", scripts[1].SQL())
+	assert.Equal(t, "ALTER TABLE reply ADD CONSTRAINT fk_reply_reply_draft FOREIGN KEY (draft_uuid) REFERENCES draft(uuid);\n", scripts[4].SQL())
+
+	assert.Equal(t, "drop_foreign_keys.sql\nreply.sql\ndraft.sql\nDraft_2.sql\nforeign_keys.sql\n", TableScriptsManifest(scripts))
+}
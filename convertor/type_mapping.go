@@ -0,0 +1,105 @@
+package convertor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// TypeLengthDefault 使用内置的长度规则
+	TypeLengthDefault = ""
+	// TypeLengthNone 不输出长度
+	TypeLengthNone = "none"
+	// TypeLengthKeep 原样输出 mysql 列的长度、精度，例如 (10,2)
+	TypeLengthKeep = "keep"
+)
+
+// TypeRule 单个类型的映射规则
+type TypeRule struct {
+	// Type 达梦类型名，为空时使用内置的映射
+	Type string `json:"type" yaml:"type"`
+	// Length 长度、精度规则：为空时使用内置规则，none 不输出长度，keep 原样输出 mysql 列的长度，
+	// 其他值作为长度原样输出，例如 4000 或 (4000 CHAR)
+	Length string `json:"length" yaml:"length"`
+}
+
+// TypeMapping 类型映射配置，优先级依次为 Columns、Tables、Types、内置的映射
+type TypeMapping struct {
+	// Types key 为 mysql 类型名，例如 longtext
+	Types map[string]TypeRule `json:"types" yaml:"types"`
+	// Tables 按表覆盖 Types，key 为表名
+	Tables map[string]map[string]TypeRule `json:"tables" yaml:"tables"`
+	// Columns 按列覆盖，key 为 表名.列名
+	Columns map[string]TypeRule `json:"columns" yaml:"columns"`
+}
+
+// LoadTypeMapping 从 JSON 或 YAML 文件（按扩展名 .json、.yaml、.yml 区分）加载类型映射配置
+func LoadTypeMapping(path string) (*TypeMapping, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping TypeMapping
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &mapping)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &mapping)
+	default:
+		return nil, fmt.Errorf("unknown type mapping file format '%s'", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid type mapping file '%s': %w", path, err)
+	}
+	return &mapping, nil
+}
+
+// lookup 返回指定列的映射规则，表名、列名、类型名不区分大小写
+func (m *TypeMapping) lookup(tableName, columnName, typeName string) (TypeRule, bool) {
+	if m == nil {
+		return TypeRule{}, false
+	}
+	if rule, found := lookupTypeRule(m.Columns, tableName+"."+columnName); found {
+		return rule, true
+	}
+	for name, types := range m.Tables {
+		if strings.EqualFold(name, tableName) {
+			if rule, found := lookupTypeNameRule(types, typeName); found {
+				return rule, true
+			}
+		}
+	}
+	return lookupTypeNameRule(m.Types, typeName)
+}
+
+// lookupTypeNameRule 按类型名查找规则，配置中的同义词（如 integer）与标准类型名（int）等同
+func lookupTypeNameRule(rules map[string]TypeRule, typeName string) (TypeRule, bool) {
+	if rule, found := lookupTypeRule(rules, typeName); found {
+		return rule, true
+	}
+	for k, rule := range rules {
+		// bool 只对应 tinyint(1)，不作用于其他 tinyint
+		if strings.EqualFold(k, "bool") || strings.EqualFold(k, "boolean") {
+			continue
+		}
+		if normalizeMysqlTypeName(k) == normalizeMysqlTypeName(typeName) {
+			return rule, true
+		}
+	}
+	return TypeRule{}, false
+}
+
+func lookupTypeRule(rules map[string]TypeRule, key string) (TypeRule, bool) {
+	for k, rule := range rules {
+		if strings.EqualFold(k, key) {
+			return rule, true
+		}
+	}
+	return TypeRule{}, false
+}
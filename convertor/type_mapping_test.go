@@ -0,0 +1,54 @@
+package convertor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTypeMapping(t *testing.T) {
+	for _, path := range []string{"testdata/type_mapping.yaml", "testdata/type_mapping.json"} {
+		t.Run(path, func(t *testing.T) {
+			m, err := LoadTypeMapping(path)
+			assert.Nil(t, err)
+			assert.Equal(t, TypeRule{Type: "CLOB"}, m.Types["longtext"])
+			assert.Equal(t, TypeRule{Type: "VARCHAR", Length: TypeLengthKeep}, m.Tables["report"]["varchar"])
+			assert.Equal(t, TypeRule{Type: "VARCHAR2", Length: "4000"}, m.Columns["report.payload"])
+		})
+	}
+
+	_, err := LoadTypeMapping("testdata/type_mapping.txt")
+	assert.NotNil(t, err)
+}
+
+func TestDMDB_TypeMapping(t *testing.T) {
+	sql := "CREATE TABLE `report` (\n" +
+		"`title` varchar(64) NOT NULL,\n" +
+		"`body` longtext,\n" +
+		"`payload` json\n" +
+		") ENGINE=InnoDB;\n" +
+		"CREATE TABLE `draft` (\n" +
+		"`title` varchar(64) NOT NULL,\n" +
+		"`body` longtext\n" +
+		") ENGINE=InnoDB;"
+
+	m, err := LoadTypeMapping("testdata/type_mapping.yaml")
+	assert.Nil(t, err)
+	got, err := NewDMDBWithOptions(sql, DMDBOptions{TypeMapping: m}).Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "CREATE TABLE report (\ntitle VARCHAR(64) NOT NULL ,\nbody CLOB ,\npayload VARCHAR2(4000) );")
+	assert.Contains(t, got, "CREATE TABLE draft (\ntitle varchar2(64 CHAR) NOT NULL ,\nbody CLOB );")
+}
+
+func TestDMDB_TypeMappingUnmappedType(t *testing.T) {
+	sql := "CREATE TABLE `event` (`id` bigint NOT NULL, `happened_at` timestamp(3) NULL DEFAULT NULL) ENGINE=InnoDB;"
+
+	m := &TypeMapping{Types: map[string]TypeRule{"timestamp": {Type: "TIMESTAMP"}}}
+	o := NewDMDBWithOptions(sql, DMDBOptions{TypeMapping: m})
+	got, err := o.Exec()
+	assert.Nil(t, err)
+	assert.Contains(t, got, "CREATE TABLE event (\nid bigint NOT NULL ,\nhappened_at TIMESTAMP );")
+	assert.Len(t, o.Diagnostics(), 1)
+	assert.Equal(t, "[INFO] event.happened_at: length of column type 'timestamp' not converted, set the length in the type mapping to keep it",
+		o.Diagnostics()[0].String())
+}
@@ -0,0 +1,545 @@
+// Package gormmodel 读取 gorm 模型结构体，生成与解析 mysql DDL 相同的表结构，再按 mysql DDL 的转换方式输出达梦 DDL，
+// 不依赖 gorm 的 AutoMigrate
+package gormmodel
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/molizz/myto/convertor"
+)
+
+// gorm.Model 的字段
+var gormModelFields = []struct {
+	name, typ, tag string
+}{
+	{"ID", "uint", "primaryKey"},
+	{"CreatedAt", "time.Time", ""},
+	{"UpdatedAt", "time.Time", ""},
+	{"DeletedAt", "gorm.DeletedAt", "index"},
+}
+
+// Load 读取 paths 中的 Go 源文件，目录读取其中除 _test.go 之外的 .go 文件（不递归）。
+// 嵌入了 gorm.Model 或带有 gorm tag 的结构体按声明的顺序转换为 CREATE TABLE
+func Load(paths ...string) (*convertor.Schema, []convertor.Diagnostic, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		names := []string{path}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			names = names[:0]
+			for _, entry := range entries {
+				name := entry.Name()
+				if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+					names = append(names, filepath.Join(path, name))
+				}
+			}
+		}
+		for _, name := range names {
+			file, err := parser.ParseFile(fset, name, nil, 0)
+			if err != nil {
+				return nil, nil, err
+			}
+			files = append(files, file)
+		}
+	}
+
+	l := newLoader(files)
+	return l.schema(), l.diagnostics.List(), nil
+}
+
+// ToDMDB 将 paths 中的 gorm 模型转换为达梦 DDL
+func ToDMDB(options convertor.DMDBOptions, paths ...string) (string, []convertor.Diagnostic, error) {
+	schema, diagnostics, err := Load(paths...)
+	if err != nil {
+		return "", nil, err
+	}
+	// type tag 中的类型未经 mysql 校验，没有对应的达梦类型时无法转换
+	for _, table := range schema.Tables() {
+		for _, column := range table.Columns {
+			if _, found := convertor.DMDBTypeName(options, table, column); !found {
+				return "", nil, fmt.Errorf("%s.%s: the mysql column type '%s' mapping was not found, set the column type by gorm type tag or DMDBOptions.TypeMapping",
+					table.Name, column.Name, column.Type.Name)
+			}
+		}
+	}
+	conv := convertor.NewDMDBWithSchema(schema, options)
+	output, err := conv.Exec()
+	return output, append(diagnostics, conv.Diagnostics()...), err
+}
+
+type loader struct {
+	types       map[string]ast.Expr // 源码中声明的类型
+	structs     []string            // 按声明顺序排列的结构体
+	tableNames  map[string]string   // TableName 方法返回的表名
+	diagnostics convertor.Diagnostics
+}
+
+func newLoader(files []*ast.File) *loader {
+	l := &loader{types: map[string]ast.Expr{}, tableNames: map[string]string{}}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						l.types[spec.Name.Name] = spec.Type
+						if _, ok := spec.Type.(*ast.StructType); ok {
+							l.structs = append(l.structs, spec.Name.Name)
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				l.readTableName(decl)
+			}
+		}
+	}
+	return l
+}
+
+// readTableName 读取 func (T) TableName() string { return "..." }
+func (l *loader) readTableName(decl *ast.FuncDecl) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil {
+		return
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if len(decl.Body.List) == 1 {
+		if ret, ok := decl.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if name, err := strconv.Unquote(lit.Value); err == nil {
+					l.tableNames[ident.Name] = name
+					return
+				}
+			}
+		}
+	}
+	l.diagnostics.Warnf(ident.Name, "", "TableName does not return a string literal, default table name used")
+}
+
+func (l *loader) schema() *convertor.Schema {
+	var schema = &convertor.Schema{}
+	for _, name := range l.structs {
+		st := l.types[name].(*ast.StructType)
+		if !l.isModel(st) {
+			continue
+		}
+		schema.Statements = append(schema.Statements, &convertor.Statement{
+			Kind:  convertor.StatementCreateTable,
+			Table: l.table(name, st),
+		})
+	}
+	return schema
+}
+
+// isModel 嵌入了 gorm.Model 或带有 gorm tag 的结构体
+func (l *loader) isModel(st *ast.StructType) bool {
+	for _, f := range st.Fields.List {
+		if _, found := fieldTag(f); found {
+			return true
+		}
+		if len(f.Names) == 0 {
+			if isGormModel(f.Type) {
+				return true
+			}
+			if embedded, ok := l.localStruct(f.Type); ok && l.isModel(embedded) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// field 结构体中对应一列的字段，嵌入的结构体已展开
+type field struct {
+	name   string // Go 字段名
+	column string
+	typ    ast.Expr
+	tags   map[string]string
+}
+
+func (l *loader) table(structName string, st *ast.StructType) *convertor.Table {
+	tableName, found := l.tableNames[structName]
+	if !found {
+		tableName = pluralize(toDBName(structName))
+	}
+	table := &convertor.Table{Name: tableName}
+
+	var fields []*field
+	l.collectFields(tableName, st, "", &fields)
+
+	// 没有 primaryKey 时名为 id 的列为主键
+	var primaryKey []*field
+	for _, f := range fields {
+		if hasTag(f.tags, "PRIMARYKEY") || hasTag(f.tags, "PRIMARY_KEY") {
+			primaryKey = append(primaryKey, f)
+		}
+	}
+	if len(primaryKey) == 0 {
+		for _, f := range fields {
+			if f.column == "id" {
+				primaryKey = append(primaryKey, f)
+			}
+		}
+	}
+
+	var indexes = &indexBuilder{tableName: tableName}
+	for _, f := range fields {
+		primary := containsField(primaryKey, f)
+		column := l.column(tableName, f, primary, len(primaryKey) == 1)
+		if column == nil {
+			continue
+		}
+		table.Columns = append(table.Columns, column)
+		indexes.add(f)
+	}
+
+	if len(primaryKey) > 0 {
+		pk := &convertor.Index{Name: "PRIMARY", Kind: convertor.IndexPrimary}
+		for _, f := range primaryKey {
+			if table.Column(f.column) != nil {
+				pk.Columns = append(pk.Columns, &convertor.IndexColumn{Name: f.column})
+			}
+		}
+		if len(pk.Columns) > 0 {
+			table.Indexes = append(table.Indexes, pk)
+		}
+	}
+	table.Indexes = append(table.Indexes, indexes.indexes()...)
+	return table
+}
+
+// collectFields 展开嵌入的结构体，跳过未导出的字段、gorm:"-" 的字段
+func (l *loader) collectFields(tableName string, st *ast.StructType, prefix string, fields *[]*field) {
+	for _, f := range st.Fields.List {
+		tag, _ := fieldTag(f)
+		tags := parseTagSetting(tag)
+		if _, found := tags["-"]; found {
+			continue
+		}
+
+		if len(f.Names) == 0 || hasTag(tags, "EMBEDDED") {
+			if len(f.Names) == 0 && isGormModel(f.Type) {
+				for _, m := range gormModelFields {
+					typ, _ := parser.ParseExpr(m.typ)
+					*fields = append(*fields, &field{name: m.name, column: prefix + toDBName(m.name), typ: typ, tags: parseTagSetting(m.tag)})
+				}
+				continue
+			}
+			if embedded, ok := l.localStruct(f.Type); ok {
+				l.collectFields(tableName, embedded, prefix+tags["EMBEDDEDPREFIX"], fields)
+				continue
+			}
+			if len(f.Names) == 0 {
+				l.diagnostics.Warnf(tableName, exprString(f.Type), "embedded field of unknown type skipped")
+				continue
+			}
+		}
+
+		for _, name := range f.Names {
+			if !name.IsExported() {
+				continue
+			}
+			column := prefix + toDBName(name.Name)
+			if c := tags["COLUMN"]; len(c) > 0 {
+				column = prefix + c
+			}
+			*fields = append(*fields, &field{name: name.Name, column: column, typ: f.Type, tags: tags})
+		}
+	}
+}
+
+// column 按 gorm mysql 驱动的规则生成列，关联等不对应列的字段返回 nil
+func (l *loader) column(tableName string, f *field, primary, singlePrimary bool) *convertor.Column {
+	column := &convertor.Column{Name: f.column, Comment: trimQuotes(f.tags["COMMENT"])}
+
+	// gorm 只为主键及 not null 的字段添加 NOT NULL，与字段是否为指针无关
+	typ := f.typ
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	var kind string
+	if t := f.tags["TYPE"]; len(t) > 0 {
+		columnType, err := convertor.ParseMysqlColumnType(t)
+		if err != nil {
+			l.diagnostics.Warnf(tableName, f.column, "%v, field skipped", err)
+			return nil
+		}
+		column.Type = columnType
+	} else {
+		if kind = l.fieldKind(typ); len(kind) == 0 {
+			if _, serializer := f.tags["SERIALIZER"]; serializer {
+				kind = "string"
+			} else {
+				if !l.isAssociation(typ) {
+					l.diagnostics.Warnf(tableName, f.column, "unsupported field type '%s' skipped, set the column type by gorm type tag", exprString(f.typ))
+				}
+				return nil
+			}
+		}
+		column.Type = l.columnType(tableName, f, kind, primary)
+	}
+
+	column.NotNull = primary || hasTag(f.tags, "NOT NULL")
+	if v, found := f.tags["AUTOINCREMENT"]; found {
+		column.AutoIncrement = !strings.EqualFold(v, "false")
+	} else if primary && singlePrimary && (strings.HasPrefix(kind, "int:") || strings.HasPrefix(kind, "uint:")) && !hasTag(f.tags, "DEFAULT") {
+		column.AutoIncrement = true
+	}
+	if v, found := f.tags["DEFAULT"]; found {
+		column.Default = defaultValue(v, column.Type)
+	}
+	return column
+}
+
+// fieldKind 返回 Go 类型对应的 gorm 数据类型：bool、int:<位数>、uint:<位数>、float:<位数>、string、time、bytes、json 等，
+// 不支持的类型返回空
+func (l *loader) fieldKind(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return "bool"
+		case "int", "int8", "int16", "int32", "int64":
+			return "int:" + strings.TrimPrefix(t.Name, "int")
+		case "uint", "uint8", "uint16", "uint32", "uint64":
+			return "uint:" + strings.TrimPrefix(t.Name, "uint")
+		case "byte":
+			return "uint:8"
+		case "float32":
+			return "float:32"
+		case "float64":
+			return "float:64"
+		case "string":
+			return "string"
+		}
+		// type Status int8 等
+		if underlying, found := l.types[t.Name]; found {
+			if _, ok := underlying.(*ast.StructType); !ok {
+				return l.fieldKind(underlying)
+			}
+		}
+	case *ast.ArrayType:
+		if elt, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (elt.Name == "byte" || elt.Name == "uint8") {
+			return "bytes"
+		}
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "time.Time", "sql.NullTime", "gorm.DeletedAt":
+			return "time"
+		case "sql.NullString":
+			return "string"
+		case "sql.NullInt64":
+			return "int:64"
+		case "sql.NullInt32":
+			return "int:32"
+		case "sql.NullInt16":
+			return "int:16"
+		case "sql.NullByte":
+			return "uint:8"
+		case "sql.NullFloat64":
+			return "float:64"
+		case "sql.NullBool":
+			return "bool"
+		case "datatypes.JSON":
+			return "json"
+		case "datatypes.Date":
+			return "date"
+		case "decimal.Decimal":
+			return "decimal"
+		}
+	}
+	return ""
+}
+
+// columnType 按 gorm mysql 驱动的 DataTypeOf 选择列类型
+func (l *loader) columnType(tableName string, f *field, kind string, primary bool) convertor.ColumnType {
+	size, _ := strconv.Atoi(f.tags["SIZE"])
+	name, bits := kind, 0
+	if i := strings.IndexByte(kind, ':'); i >= 0 {
+		name = kind[:i]
+		if bits, _ = strconv.Atoi(kind[i+1:]); bits == 0 {
+			bits = 64
+		}
+		if size > 0 {
+			bits = size
+		}
+	}
+
+	switch name {
+	case "bool":
+		return convertor.ColumnType{Name: "tinyint", Length: intPtr(1)}
+	case "int", "uint":
+		var columnType convertor.ColumnType
+		switch {
+		case bits <= 8:
+			columnType.Name = "tinyint"
+		case bits <= 16:
+			columnType.Name = "smallint"
+		case bits <= 24:
+			columnType.Name = "mediumint"
+		case bits <= 32:
+			columnType.Name = "int"
+		default:
+			columnType.Name = "bigint"
+		}
+		columnType.Unsigned = name == "uint"
+		return columnType
+	case "float":
+		if bits <= 32 {
+			return convertor.ColumnType{Name: "float"}
+		}
+		return convertor.ColumnType{Name: "double"}
+	case "string":
+		if size == 0 && (primary || hasTag(f.tags, "DEFAULT") || hasTag(f.tags, "INDEX") ||
+			hasTag(f.tags, "UNIQUEINDEX") || hasTag(f.tags, "UNIQUE")) {
+			size = 191
+		}
+		switch {
+		case size >= 65536 && size <= 1<<24:
+			return convertor.ColumnType{Name: "mediumtext"}
+		case size > 1<<24 || size <= 0:
+			return convertor.ColumnType{Name: "longtext"}
+		}
+		return convertor.ColumnType{Name: "varchar", Length: intPtr(size)}
+	case "bytes":
+		switch {
+		case size > 0 && size < 65536:
+			return convertor.ColumnType{Name: "varbinary", Length: intPtr(size)}
+		case size >= 65536 && size <= 1<<24:
+			return convertor.ColumnType{Name: "mediumblob"}
+		}
+		return convertor.ColumnType{Name: "longblob"}
+	case "time":
+		precision := 3
+		if p, err := strconv.Atoi(f.tags["PRECISION"]); err == nil {
+			precision = p
+		}
+		return convertor.ColumnType{Name: "datetime", Length: intPtr(precision)}
+	case "decimal":
+		precision, errP := strconv.Atoi(f.tags["PRECISION"])
+		scale, _ := strconv.Atoi(f.tags["SCALE"])
+		if errP != nil {
+			l.diagnostics.Warnf(tableName, f.column, "decimal field without precision, decimal(10,0) used")
+			precision = 10
+		}
+		return convertor.ColumnType{Name: "decimal", Length: intPtr(precision), Scale: intPtr(scale)}
+	}
+	return convertor.ColumnType{Name: name}
+}
+
+// isAssociation 字段为模型结构体、其指针或切片，即 gorm 的关联
+func (l *loader) isAssociation(typ ast.Expr) bool {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return l.isAssociation(t.X)
+	case *ast.ArrayType:
+		return l.isAssociation(t.Elt)
+	case *ast.Ident:
+		_, ok := l.types[t.Name].(*ast.StructType)
+		return ok
+	}
+	return false
+}
+
+// localStruct 返回源码中声明的结构体类型
+func (l *loader) localStruct(typ ast.Expr) (*ast.StructType, bool) {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		st, ok := l.types[ident.Name].(*ast.StructType)
+		return st, ok
+	}
+	return nil, false
+}
+
+func isGormModel(typ ast.Expr) bool {
+	return exprString(typ) == "gorm.Model"
+}
+
+func fieldTag(f *ast.Field) (string, bool) {
+	if f.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(tag).Lookup("gorm")
+}
+
+func containsField(fields []*field, f *field) bool {
+	for _, x := range fields {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValue 解析 default tag，字符串类型的列按字符串处理
+func defaultValue(v string, columnType convertor.ColumnType) *convertor.DefaultValue {
+	switch {
+	case strings.EqualFold(v, "null"):
+		return &convertor.DefaultValue{Kind: convertor.DefaultNull}
+	case strings.HasPrefix(strings.ToUpper(v), "CURRENT_TIMESTAMP"):
+		return &convertor.DefaultValue{Kind: convertor.DefaultCurrentTimestamp, Value: "CURRENT_TIMESTAMP"}
+	case len(v) >= 2 && (v[0] == '\'' || v[0] == '"'):
+		return &convertor.DefaultValue{Kind: convertor.DefaultString, Value: trimQuotes(v)}
+	case strings.EqualFold(v, "true"):
+		return &convertor.DefaultValue{Kind: convertor.DefaultNumber, Value: "1"}
+	case strings.EqualFold(v, "false"):
+		return &convertor.DefaultValue{Kind: convertor.DefaultNumber, Value: "0"}
+	}
+	switch columnType.Name {
+	case "varchar", "char", "mediumtext", "longtext", "text":
+		return &convertor.DefaultValue{Kind: convertor.DefaultString, Value: v}
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return &convertor.DefaultValue{Kind: convertor.DefaultNumber, Value: v}
+	}
+	return &convertor.DefaultValue{Kind: convertor.DefaultString, Value: v}
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", exprString(t.Key), exprString(t.Value))
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
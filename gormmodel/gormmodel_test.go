@@ -0,0 +1,131 @@
+package gormmodel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/molizz/myto/convertor"
+	"github.com/stretchr/testify/assert"
+)
+
+const testModels = `package model
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type User struct {
+	gorm.Model
+	Name     string ` + "`gorm:\"size:64;not null;index:idx_name_age,priority:2;comment:名称\"`" + `
+	Age      int    ` + "`gorm:\"default:18;index:idx_name_age,priority:1\"`" + `
+	Email    string ` + "`gorm:\"uniqueIndex\"`" + `
+	Bio      string
+	Score    sql.NullFloat64
+	Birthday *time.Time
+	Profile  Profile
+	Orders   []Order
+	Ignored  string ` + "`gorm:\"-\"`" + `
+}
+
+type Profile struct {
+	ID     uint64
+	UserID uint
+	Flag   byte
+	Avatar []byte ` + "`gorm:\"type:varchar(255)\"`" + `
+}
+
+func (Profile) TableName() string { return "user_profile" }
+
+type Order struct {
+	ID     string  ` + "`gorm:\"primaryKey;size:36\"`" + `
+	Amount float64 ` + "`gorm:\"type:decimal(10,2)\"`" + `
+}
+`
+
+func writeTestModels(t *testing.T) string {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "model.go"), []byte(testModels), 0644))
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	schema, diagnostics, err := Load(writeTestModels(t))
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+
+	tables := schema.Tables()
+	assert.Equal(t, 3, len(tables))
+	assert.Equal(t, "users", tables[0].Name)
+	assert.Equal(t, "user_profile", tables[1].Name)
+	assert.Equal(t, "orders", tables[2].Name)
+
+	users := tables[0]
+	var names []string
+	for _, column := range users.Columns {
+		names = append(names, column.Name)
+	}
+	assert.Equal(t, []string{"id", "created_at", "updated_at", "deleted_at", "name", "age", "email", "bio", "score", "birthday"}, names)
+	assert.True(t, users.Columns[0].AutoIncrement)
+	assert.Equal(t, "varchar", users.Columns[4].Type.Name)
+	assert.Equal(t, "名称", users.Columns[4].Comment)
+	assert.Equal(t, "longtext", users.Columns[7].Type.Name)
+
+	index := users.Indexes[2]
+	assert.Equal(t, "idx_name_age", index.Name)
+	assert.Equal(t, []string{"age", "name"}, index.ColumnNames())
+	assert.Equal(t, convertor.IndexUnique, users.Indexes[3].Kind)
+	assert.Equal(t, "idx_users_email", users.Indexes[3].Name)
+
+	assert.Equal(t, convertor.ColumnType{Name: "tinyint", Unsigned: true}, tables[1].Columns[2].Type)
+	assert.False(t, tables[2].Columns[0].AutoIncrement)
+}
+
+func TestToDMDB(t *testing.T) {
+	output, _, err := ToDMDB(convertor.DMDBOptions{}, filepath.Join(writeTestModels(t), "model.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, output, "CREATE TABLE user_profile (\nid bigint NOT NULL ,\nuser_id bigint ,\nflag smallint ,\navatar varchar2(255 CHAR) );")
+	assert.Contains(t, output, "CREATE TABLE orders (\nid varchar2(36 CHAR) NOT NULL ,\namount numeric(10,2) );")
+	assert.Contains(t, output, "CREATE INDEX idx_users_idx_name_age ON users(age, name);")
+	assert.Contains(t, output, "COMMENT ON COLUMN users.name IS '名称';")
+}
+
+func TestToDMDB_UnmappedType(t *testing.T) {
+	dir := t.TempDir()
+	model := `package model
+
+type Event struct {
+	ID uint
+	At string ` + "`gorm:\"type:timestamp\"`" + `
+}
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "model.go"), []byte(model), 0644))
+
+	_, _, err := ToDMDB(convertor.DMDBOptions{}, dir)
+	assert.EqualError(t, err, "events.at: the mysql column type 'timestamp' mapping was not found, set the column type by gorm type tag or DMDBOptions.TypeMapping")
+}
+
+func TestToDBName(t *testing.T) {
+	assert.Equal(t, "user_id", toDBName("UserID"))
+	assert.Equal(t, "http_server", toDBName("HTTPServer"))
+	assert.Equal(t, "created_at", toDBName("CreatedAt"))
+	assert.Equal(t, "address2", toDBName("Address2"))
+}
+
+func TestPluralize(t *testing.T) {
+	assert.Equal(t, "users", pluralize("user"))
+	assert.Equal(t, "categories", pluralize("category"))
+	assert.Equal(t, "boxes", pluralize("box"))
+	assert.Equal(t, "keys", pluralize("key"))
+}
+
+func TestParseTagSetting(t *testing.T) {
+	tags := parseTagSetting(`column:name;not null;default:'a\;b';comment:x:y`)
+	assert.Equal(t, "name", tags["COLUMN"])
+	assert.True(t, hasTag(tags, "NOT NULL"))
+	assert.Equal(t, "'a;b'", tags["DEFAULT"])
+	assert.Equal(t, "x:y", tags["COMMENT"])
+}
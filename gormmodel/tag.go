@@ -0,0 +1,165 @@
+package gormmodel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/molizz/myto/convertor"
+)
+
+// parseTagSetting 按 gorm 的规则解析 tag：以 ; 分隔，键不区分大小写，\; 不作为分隔符
+func parseTagSetting(tag string) map[string]string {
+	settings := map[string]string{}
+	names := strings.Split(tag, ";")
+	for i := 0; i < len(names); i++ {
+		for strings.HasSuffix(names[i], "\\") && i+1 < len(names) {
+			names[i] = names[i][:len(names[i])-1] + ";" + names[i+1]
+			names = append(names[:i+1], names[i+2:]...)
+		}
+		values := strings.SplitN(names[i], ":", 2)
+		key := strings.ToUpper(strings.TrimSpace(values[0]))
+		if len(key) == 0 {
+			continue
+		}
+		if len(values) == 2 {
+			settings[key] = strings.TrimSpace(values[1])
+		} else {
+			settings[key] = key
+		}
+	}
+	return settings
+}
+
+func hasTag(tags map[string]string, key string) bool {
+	_, found := tags[key]
+	return found
+}
+
+func trimQuotes(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// toDBName 按 gorm 默认的命名规则将字段名转换为列名，如 UserID 转换为 user_id
+func toDBName(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// pluralize 表名的复数形式，只处理常见的规则，不规则的名词需要通过 TableName 方法指定表名
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") || strings.HasSuffix(name, "z") ||
+		strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+// indexBuilder 按 index、uniqueIndex、unique tag 生成索引，同名的索引合并为联合索引
+type indexBuilder struct {
+	tableName string
+	names     []string
+	columns   map[string][]*indexColumn
+	kinds     map[string]convertor.IndexKind
+}
+
+type indexColumn struct {
+	*convertor.IndexColumn
+	priority int
+}
+
+func (b *indexBuilder) add(f *field) {
+	if v, found := f.tags["INDEX"]; found {
+		b.addIndex(f, v, convertor.IndexNormal)
+	}
+	if v, found := f.tags["UNIQUEINDEX"]; found {
+		b.addIndex(f, v, convertor.IndexUnique)
+	}
+	if hasTag(f.tags, "UNIQUE") {
+		b.addColumn(f.column, convertor.IndexUnique, &indexColumn{IndexColumn: &convertor.IndexColumn{Name: f.column}})
+	}
+}
+
+// addIndex 解析 index:name,unique,class:FULLTEXT,length:10,priority:1 等设置
+func (b *indexBuilder) addIndex(f *field, value string, kind convertor.IndexKind) {
+	var name string
+	var settings = map[string]string{}
+	// 没有设置时值为 tag 的键
+	if value != "INDEX" && value != "UNIQUEINDEX" {
+		parts := strings.Split(value, ",")
+		name = strings.TrimSpace(parts[0])
+		settings = parseTagSetting(strings.Join(parts[1:], ";"))
+	}
+	if len(name) == 0 {
+		name = "idx_" + b.tableName + "_" + f.column
+	}
+
+	switch strings.ToUpper(settings["CLASS"]) {
+	case "UNIQUE":
+		kind = convertor.IndexUnique
+	case "FULLTEXT":
+		kind = convertor.IndexFulltext
+	case "SPATIAL":
+		kind = convertor.IndexSpatial
+	}
+	if hasTag(settings, "UNIQUE") {
+		kind = convertor.IndexUnique
+	}
+
+	column := &indexColumn{IndexColumn: &convertor.IndexColumn{Name: f.column}, priority: 10}
+	if length, err := strconv.Atoi(settings["LENGTH"]); err == nil {
+		column.Length = &length
+	}
+	if priority, err := strconv.Atoi(settings["PRIORITY"]); err == nil {
+		column.priority = priority
+	}
+	b.addColumn(name, kind, column)
+}
+
+func (b *indexBuilder) addColumn(name string, kind convertor.IndexKind, column *indexColumn) {
+	if b.columns == nil {
+		b.columns, b.kinds = map[string][]*indexColumn{}, map[string]convertor.IndexKind{}
+	}
+	if _, found := b.columns[name]; !found {
+		b.names = append(b.names, name)
+		b.kinds[name] = kind
+	} else if kind != convertor.IndexNormal {
+		b.kinds[name] = kind
+	}
+	b.columns[name] = append(b.columns[name], column)
+}
+
+func (b *indexBuilder) indexes() []*convertor.Index {
+	var indexes []*convertor.Index
+	for _, name := range b.names {
+		columns := b.columns[name]
+		sort.SliceStable(columns, func(i, j int) bool {
+			return columns[i].priority < columns[j].priority
+		})
+		index := &convertor.Index{Name: name, Kind: b.kinds[name]}
+		for _, column := range columns {
+			index.Columns = append(index.Columns, column.IndexColumn)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
@@ -2,30 +2,137 @@ package myto
 
 import (
 	"github.com/molizz/myto/convertor"
-	"github.com/xwb1989/sqlparser"
 )
 
 type Convertor interface {
 	Exec() (string, error)
+	Diagnostics() []convertor.Diagnostic
+}
+
+// DownConvertor 可以同时输出回滚语句的 Convertor
+type DownConvertor interface {
+	Convertor
+	ExecDown() (string, error)
 }
 
 type Myto struct {
-	isDDL        bool
-	sql          string
-	sqlTokenizer *sqlparser.Tokenizer
+	isDDL       bool
+	sql         string
+	dmdbOptions convertor.DMDBOptions
+	diagnostics []convertor.Diagnostic
 }
 
 func New(sql string, isDDL bool) *Myto {
-	sqlTokenizer := sqlparser.NewStringTokenizer(sql)
 	return &Myto{
-		sql:          sql,
-		isDDL:        isDDL,
-		sqlTokenizer: sqlTokenizer,
+		sql:   sql,
+		isDDL: isDDL,
 	}
 }
 
+// WithDMDBOptions 设置转换为达梦数据库时的选项
+func (m *Myto) WithDMDBOptions(options convertor.DMDBOptions) *Myto {
+	m.dmdbOptions = options
+	return m
+}
+
 // ToDMDB 达梦数据库
 func (m *Myto) ToDMDB() (string, error) {
-	var conv Convertor = convertor.NewDMDB(m.sqlTokenizer)
-	return conv.Exec()
+	var conv Convertor = convertor.NewDMDBWithOptions(m.sql, m.dmdbOptions)
+	output, err := conv.Exec()
+	m.diagnostics = conv.Diagnostics()
+	return output, err
+}
+
+// ToDMDBDiff 比较 oldSQL 与当前的 mysql DDL，输出将已按 oldSQL 转换的达梦数据库迁移到新结构的语句
+func (m *Myto) ToDMDBDiff(oldSQL string) (string, error) {
+	var conv Convertor = convertor.NewDMDBDiff(oldSQL, m.sql, m.dmdbOptions)
+	output, err := conv.Exec()
+	m.diagnostics = conv.Diagnostics()
+	return output, err
+}
+
+// ToDMDBWithDown 同 ToDMDB，同时返回回滚 up 的 down 语句
+func (m *Myto) ToDMDBWithDown() (up, down string, err error) {
+	return m.execWithDown(convertor.NewDMDBWithOptions(m.sql, m.dmdbOptions))
+}
+
+// ToDMDBDiffWithDown 同 ToDMDBDiff，同时返回从新结构回滚到 oldSQL 的 down 语句
+func (m *Myto) ToDMDBDiffWithDown(oldSQL string) (up, down string, err error) {
+	return m.execWithDown(convertor.NewDMDBDiff(oldSQL, m.sql, m.dmdbOptions))
+}
+
+func (m *Myto) execWithDown(conv DownConvertor) (up, down string, err error) {
+	defer func() {
+		m.diagnostics = conv.Diagnostics()
+	}()
+	if up, err = conv.Exec(); err != nil {
+		return "", "", err
+	}
+	if down, err = conv.ExecDown(); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+// ToFlyway 将转换结果拆分为 Flyway 版本化脚本，版本号从 startVersion 开始
+func (m *Myto) ToFlyway(startVersion int) ([]*convertor.FlywayScript, error) {
+	objects, err := m.ToDMDBObjects()
+	if err != nil {
+		return nil, err
+	}
+	return convertor.FlywayScripts(objects, startVersion), nil
+}
+
+// ToLiquibase 将转换结果输出为 Liquibase changelog，每个表、索引、注释等对象一个 changeSet
+func (m *Myto) ToLiquibase(format convertor.LiquibaseFormat, author string) (string, error) {
+	objects, err := m.ToDMDBObjects()
+	if err != nil {
+		return "", err
+	}
+	return convertor.LiquibaseChangelog(objects, format, author)
+}
+
+// ToDMDBObjects 按执行顺序返回转换结果中的表、索引、注释、约束等对象
+func (m *Myto) ToDMDBObjects() ([]*convertor.DMDBObject, error) {
+	conv := convertor.NewDMDBWithOptions(m.sql, m.dmdbOptions)
+	objects, err := conv.Objects()
+	m.diagnostics = conv.Diagnostics()
+	return objects, err
+}
+
+// ToTableScripts 将转换结果按表拆分为脚本，外键单独成为脚本
+func (m *Myto) ToTableScripts() ([]*convertor.TableScript, error) {
+	objects, err := m.ToDMDBObjects()
+	if err != nil {
+		return nil, err
+	}
+	return convertor.TableScripts(objects), nil
+}
+
+// ToGoStructs 为 CREATE TABLE 的表生成 Go 结构体，字段类型按转换为达梦后的类型选择
+func (m *Myto) ToGoStructs(options convertor.GoStructOptions) (string, error) {
+	conv := convertor.NewDMDBWithOptions(m.sql, m.dmdbOptions)
+	output, err := conv.GoStructs(options)
+	m.diagnostics = conv.Diagnostics()
+	return output, err
+}
+
+// ToMysql 将 myto 输出的达梦 DDL 转换回 mysql DDL
+func (m *Myto) ToMysql() (string, error) {
+	var conv Convertor = convertor.NewMysqlDB(m.sql)
+	output, err := conv.Exec()
+	m.diagnostics = conv.Diagnostics()
+	return output, err
+}
+
+// ToJSON 输出解析得到的表结构（表、列、索引、表选项），结构见 convertor.SchemaDocument
+func (m *Myto) ToJSON() (string, error) {
+	schema, diagnostics := convertor.ParseMysqlSchema(m.sql)
+	m.diagnostics = diagnostics
+	return schema.ToJSON()
+}
+
+// Diagnostics 返回最近一次转换产生的提示（被改写或跳过的对象等）
+func (m *Myto) Diagnostics() []convertor.Diagnostic {
+	return m.diagnostics
 }